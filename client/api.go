@@ -0,0 +1,407 @@
+package client
+
+import (
+	"context"
+	"fabric-client/sign"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 请求体附加时间戳和签名
+type signedBody struct {
+	Timestamp int64
+	Sign      string
+}
+
+// CreateChannel 创建通道
+func (client *Client) CreateChannel(ctx context.Context, request *ChannelRequest) error {
+	return client.postChannel(ctx, "/api/channel/create", request)
+}
+
+// JoinChannel 组织的节点加入通道
+func (client *Client) JoinChannel(ctx context.Context, request *ChannelRequest) error {
+	return client.postChannel(ctx, "/api/chaincode/join", request)
+}
+
+func (client *Client) postChannel(ctx context.Context, path string, request *ChannelRequest) error {
+	timestamp := client.timestamp()
+	src := "orgName=" + request.OrgName + "&channelID=" + request.ChannelID + "&timestamp=" + strconv.FormatInt(timestamp, 10)
+	body := struct {
+		*ChannelRequest
+		signedBody
+	}{request, signedBody{Timestamp: timestamp, Sign: sign.Sign(src)}}
+	_, err := client.call(ctx, http.MethodPost, path, nil, body, nil)
+	return err
+}
+
+// InstallChaincode 安装链码
+func (client *Client) InstallChaincode(ctx context.Context, request *CCRequest) error {
+	src := "orgName=" + request.OrgName + "&chaincodeID=" + request.ChaincodeID + "&chaincodeVersion=" + request.ChaincodeVersion + "&chaincodePath=" + request.ChaincodePath
+	return client.postCC(ctx, "/api/chaincode/install", request, src)
+}
+
+// InstantiateChaincode 实例化链码
+func (client *Client) InstantiateChaincode(ctx context.Context, request *CCRequest) error {
+	return client.postCC(ctx, "/api/chaincode/instantiate", request, ccSignSource(request))
+}
+
+// UpgradeChaincode 升级链码
+func (client *Client) UpgradeChaincode(ctx context.Context, request *CCRequest) error {
+	return client.postCC(ctx, "/api/chaincode/upgrade", request, ccSignSource(request))
+}
+
+func ccSignSource(request *CCRequest) string {
+	return "channelID=" + request.ChannelID + "&orgName=" + request.OrgName + "&chaincodeID=" + request.ChaincodeID +
+		"&chaincodeVersion=" + request.ChaincodeVersion + "&chaincodePath=" + request.ChaincodePath
+}
+
+func (client *Client) postCC(ctx context.Context, path string, request *CCRequest, src string) error {
+	timestamp := client.timestamp()
+	src += "&timestamp=" + strconv.FormatInt(timestamp, 10)
+	body := struct {
+		*CCRequest
+		signedBody
+	}{request, signedBody{Timestamp: timestamp, Sign: sign.Sign(src)}}
+	_, err := client.call(ctx, http.MethodPost, path, nil, body, nil)
+	return err
+}
+
+// Exec 同步执行链码，等待交易提交后返回，忽略request中的Async和DryRun
+func (client *Client) Exec(ctx context.Context, request *ChaincodeRequest) (*TxResponse, error) {
+	execRequest := *request
+	execRequest.Async, execRequest.DryRun = false, false
+
+	response := &TxResponse{}
+	result, err := client.postExec(ctx, &execRequest, response)
+	if err != nil {
+		return nil, err
+	}
+	response.Warning = result.Warning
+	return response, nil
+}
+
+// ExecAsync 异步执行链码，提交到排序节点后立即返回，通过TxStatus查询提交结果
+func (client *Client) ExecAsync(ctx context.Context, request *ChaincodeRequest) (*TxState, error) {
+	execRequest := *request
+	execRequest.Async, execRequest.DryRun = true, false
+
+	state := &TxState{}
+	if _, err := client.postExec(ctx, &execRequest, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Simulate 只收集背书，不提交交易，背书结果不一致时返回错误码23，Error.Data为模拟结果
+func (client *Client) Simulate(ctx context.Context, request *ChaincodeRequest) (*SimulationResult, error) {
+	execRequest := *request
+	execRequest.Async, execRequest.DryRun = false, true
+
+	simulation := &SimulationResult{}
+	if _, err := client.postExec(ctx, &execRequest, simulation); err != nil {
+		return nil, err
+	}
+	return simulation, nil
+}
+
+func (client *Client) postExec(ctx context.Context, request *ChaincodeRequest, data interface{}) (*Result, error) {
+	timestamp := client.timestamp()
+	src := execSignSource(request) + "&timestamp=" + strconv.FormatInt(timestamp, 10)
+	body := struct {
+		*ChaincodeRequest
+		signedBody
+	}{request, signedBody{Timestamp: timestamp, Sign: sign.Sign(src)}}
+	return client.call(ctx, http.MethodPost, "/api/chaincode/exec", nil, body, data)
+}
+
+// Batch 批量执行链码，部分失败时不返回错误，各项的结果见BatchResult.Items
+func (client *Client) Batch(ctx context.Context, requests []*ChaincodeRequest) (*BatchResult, error) {
+	sources := make([]string, len(requests))
+	for i, request := range requests {
+		sources[i] = execSignSource(request)
+	}
+	timestamp := client.timestamp()
+	src := strings.Join(sources, "|") + "&timestamp=" + strconv.FormatInt(timestamp, 10)
+	body := struct {
+		Requests []*ChaincodeRequest
+		signedBody
+	}{requests, signedBody{Timestamp: timestamp, Sign: sign.Sign(src)}}
+
+	batchResult := &BatchResult{}
+	result, err := client.call(ctx, http.MethodPost, "/api/chaincode/batch", nil, body, batchResult)
+	if apiErr, ok := err.(*Error); ok && apiErr.Code == CodeBatchPartialError {
+		err = decodeData(result, batchResult)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return batchResult, nil
+}
+
+// Query 查询链码，不提交交易
+func (client *Client) Query(ctx context.Context, request *ChaincodeRequest) (*TxResponse, error) {
+	timestamp := client.timestamp()
+	src := "args[0]=" + argAt(request.Args, 0) + "&channelID=" + request.ChannelID + "&orgName=" + request.OrgName + "&userName=" + request.UserName +
+		argsSignSource(request.Args) + "&chaincodeID=" + request.ChaincodeID + "&fcn=" + request.Fcn + optionsSignSource(request) +
+		"&timestamp=" + strconv.FormatInt(timestamp, 10)
+	body := struct {
+		*ChaincodeRequest
+		signedBody
+	}{request, signedBody{Timestamp: timestamp, Sign: sign.Sign(src)}}
+
+	response := &TxResponse{}
+	if _, err := client.call(ctx, http.MethodPost, "/api/chaincode/query", nil, body, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// TxStatus 查询交易状态，交易不存在时返回错误码19
+func (client *Client) TxStatus(ctx context.Context, txID string) (*TxState, error) {
+	timestamp := client.timestamp()
+	src := "txID=" + txID + "&timestamp=" + strconv.FormatInt(timestamp, 10)
+
+	state := &TxState{}
+	if _, err := client.call(ctx, http.MethodGet, "/api/tx/"+url.PathEscape(txID), signQuery(timestamp, src), nil, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// TxHistory 查询交易历史
+func (client *Client) TxHistory(ctx context.Context, query *TxHistoryQuery) (*TxHistoryPage, error) {
+	timestamp := client.timestamp()
+	startTime, endTime := strconv.FormatInt(query.StartTime, 10), strconv.FormatInt(query.EndTime, 10)
+	src := "chaincodeID=" + query.ChaincodeID + "&fcn=" + query.Fcn + "&orgName=" + query.OrgName + "&userName=" + query.UserName + "&status=" + query.Status +
+		"&startTime=" + startTime + "&endTime=" + endTime +
+		"&PageNumber=" + strconv.Itoa(atLeastOne(query.PageNumber)) + "&Limit=" + strconv.Itoa(atLeastOne(query.PageSize)) +
+		timeSignSource(query.Timezone, query.TimeFormat) + "&timestamp=" + strconv.FormatInt(timestamp, 10)
+
+	values := signQuery(timestamp, src)
+	values.Set("pageNumber", strconv.Itoa(query.PageNumber))
+	values.Set("pageSize", strconv.Itoa(query.PageSize))
+	values.Set("chaincodeID", query.ChaincodeID)
+	values.Set("fcn", query.Fcn)
+	values.Set("orgName", query.OrgName)
+	values.Set("userName", query.UserName)
+	values.Set("status", query.Status)
+	values.Set("startTime", startTime)
+	values.Set("endTime", endTime)
+	setTimeParams(values, query.Timezone, query.TimeFormat)
+
+	page := &TxHistoryPage{}
+	if _, err := client.call(ctx, http.MethodGet, "/api/tx/history", values, nil, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// Blocks 区块交易分页查询
+func (client *Client) Blocks(ctx context.Context, query *BlockQuery) (*BlockPage, error) {
+	timestamp := client.timestamp()
+	src := "SortOrder=" + query.SortOrder + "&PageNumber=" + strconv.Itoa(atLeastOne(query.PageNumber)) + "&SortName=" + query.SortName +
+		"&StartDate=" + query.StartDate + "&Limit=" + strconv.Itoa(atLeastOne(query.PageSize)) +
+		query.BlockFilter.signSource() + "&timestamp=" + strconv.FormatInt(timestamp, 10)
+
+	values := signQuery(timestamp, src)
+	values.Set("pageNumber", strconv.Itoa(query.PageNumber))
+	values.Set("pageSize", strconv.Itoa(query.PageSize))
+	values.Set("sortName", query.SortName)
+	values.Set("sortOrder", query.SortOrder)
+	query.BlockFilter.setParams(values)
+
+	page := &BlockPage{}
+	if _, err := client.call(ctx, http.MethodGet, "/api/pagination/block", values, nil, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// BlocksCursor 区块交易游标分页查询，返回的NextCursor为空时没有下一页
+func (client *Client) BlocksCursor(ctx context.Context, query *BlockCursorQuery) (*BlockCursorPage, error) {
+	sortOrder := query.SortOrder
+	if sortOrder == "" {
+		sortOrder = "asc"
+	}
+	timestamp := client.timestamp()
+	src := "Cursor=" + query.Cursor + "&SortOrder=" + sortOrder + "&StartDate=" + query.StartDate + "&Limit=" + strconv.Itoa(atLeastOne(query.PageSize)) +
+		query.BlockFilter.signSource() + "&timestamp=" + strconv.FormatInt(timestamp, 10)
+
+	values := signQuery(timestamp, src)
+	values.Set("pageSize", strconv.Itoa(query.PageSize))
+	values.Set("cursor", query.Cursor)
+	values.Set("sortOrder", sortOrder)
+	query.BlockFilter.setParams(values)
+
+	page := &BlockCursorPage{}
+	if _, err := client.call(ctx, http.MethodGet, "/api/pagination/block/cursor", values, nil, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// Reconcile 对账账本与区块索引，Repair为true时按账本修复索引
+func (client *Client) Reconcile(ctx context.Context, request *ReconcileRequest) (*ReconcileReport, error) {
+	timestamp := client.timestamp()
+	src := "channelID=" + request.ChannelID + "&orgName=" + request.OrgName + "&userName=" + request.UserName +
+		"&startBlock=" + strconv.FormatUint(request.StartBlock, 10) + "&endBlock=" + strconv.FormatUint(request.EndBlock, 10)
+	if request.Repair {
+		src += "&repair=true"
+	}
+	src += "&timestamp=" + strconv.FormatInt(timestamp, 10)
+	body := struct {
+		*ReconcileRequest
+		signedBody
+	}{request, signedBody{Timestamp: timestamp, Sign: sign.Sign(src)}}
+
+	report := &ReconcileReport{}
+	if _, err := client.call(ctx, http.MethodPost, "/api/block/reconcile", nil, body, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Diagnostics 各组织的节点诊断信息
+func (client *Client) Diagnostics(ctx context.Context) ([]*OrgDiagnostics, error) {
+	timestamp := client.timestamp()
+	src := "timestamp=" + strconv.FormatInt(timestamp, 10)
+
+	diagnostics := make([]*OrgDiagnostics, 0)
+	if _, err := client.call(ctx, http.MethodGet, "/api/diagnostics", signQuery(timestamp, src), nil, &diagnostics); err != nil {
+		return nil, err
+	}
+	return diagnostics, nil
+}
+
+// ErrorCatalog 错误码对应的字符串标识和HTTP状态码
+func (client *Client) ErrorCatalog(ctx context.Context) ([]ErrorInfo, error) {
+	catalog := make([]ErrorInfo, 0)
+	if _, err := client.call(ctx, http.MethodGet, "/api/errors", nil, nil, &catalog); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// 链码执行签名原串，不包含时间戳，与服务端的拼接规则一致
+func execSignSource(request *ChaincodeRequest) string {
+	src := "args[0]=" + argAt(request.Args, 0) + "&channelID=" + request.ChannelID + "&orgName=" + request.OrgName + "&userName=" + request.UserName +
+		argsSignSource(request.Args) + "&chaincodeID=" + request.ChaincodeID + "&fcn=" + request.Fcn + "&eventCallbackUrl=" + request.EventCallbackUrl
+	if request.RequestID != "" {
+		src += "&requestID=" + request.RequestID
+	}
+	if request.Async {
+		src += "&async=true"
+	}
+	if request.DryRun {
+		src += "&dryRun=true"
+	}
+	return src + optionsSignSource(request)
+}
+
+// 第一个之后的参数
+func argsSignSource(args []string) string {
+	src := ""
+	for i := 1; i < len(args); i++ {
+		src += "&args[" + strconv.Itoa(i) + "]=" + args[i]
+	}
+	return src
+}
+
+func argAt(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
+
+// 请求选项的签名原串，未设置的选项不参与签名
+func optionsSignSource(request *ChaincodeRequest) string {
+	src := ""
+	if len(request.TargetPeers) > 0 {
+		src += "&targetPeers=" + strings.Join(request.TargetPeers, ",")
+	}
+	if request.Timeout != 0 {
+		src += "&timeout=" + strconv.FormatInt(request.Timeout, 10)
+	}
+	if request.RetryAttempts != 0 {
+		src += "&retryAttempts=" + strconv.Itoa(request.RetryAttempts)
+	}
+	if request.RetryBackoff != 0 {
+		src += "&retryBackoff=" + strconv.FormatInt(request.RetryBackoff, 10)
+	}
+	return src
+}
+
+// 过滤条件的签名原串，未设置的条件不参与签名
+func (filter BlockFilter) signSource() string {
+	src := ""
+	if filter.EndDate != "" {
+		src += "&EndDate=" + filter.EndDate
+	}
+	if filter.ChannelID != "" {
+		src += "&ChannelID=" + filter.ChannelID
+	}
+	if filter.TxIDPrefix != "" {
+		src += "&TxIDPrefix=" + filter.TxIDPrefix
+	}
+	if filter.MinNumber != nil && *filter.MinNumber >= 0 {
+		src += "&MinNumber=" + strconv.FormatInt(*filter.MinNumber, 10)
+	}
+	if filter.MaxNumber != nil && *filter.MaxNumber >= 0 {
+		src += "&MaxNumber=" + strconv.FormatInt(*filter.MaxNumber, 10)
+	}
+	return src + timeSignSource(filter.Timezone, filter.TimeFormat)
+}
+
+func (filter BlockFilter) setParams(values url.Values) {
+	values.Set("startDate", filter.StartDate)
+	values.Set("endDate", filter.EndDate)
+	values.Set("channelID", filter.ChannelID)
+	values.Set("txIDPrefix", filter.TxIDPrefix)
+	if filter.MinNumber != nil {
+		values.Set("minNumber", strconv.FormatInt(*filter.MinNumber, 10))
+	}
+	if filter.MaxNumber != nil {
+		values.Set("maxNumber", strconv.FormatInt(*filter.MaxNumber, 10))
+	}
+	setTimeParams(values, filter.Timezone, filter.TimeFormat)
+}
+
+// 时区和时间格式的签名原串，unix格式不参与签名
+func timeSignSource(timezone string, timeFormat string) string {
+	src := ""
+	if timezone != "" {
+		src += "&Timezone=" + timezone
+	}
+	if timeFormat != "" && timeFormat != "unix" {
+		src += "&TimeFormat=" + timeFormat
+	}
+	return src
+}
+
+func setTimeParams(values url.Values, timezone string, timeFormat string) {
+	if timezone != "" {
+		values.Set("tz", timezone)
+	}
+	if timeFormat != "" {
+		values.Set("timeFormat", timeFormat)
+	}
+}
+
+// 带时间戳和签名的查询参数
+func signQuery(timestamp int64, src string) url.Values {
+	values := url.Values{}
+	values.Set("timestamp", strconv.FormatInt(timestamp, 10))
+	values.Set("sign", sign.Sign(src))
+	return values
+}
+
+// 与服务端分页参数的处理一致，小于1时按1计算
+func atLeastOne(value int) int {
+	if value < 1 {
+		return 1
+	}
+	return value
+}
@@ -0,0 +1,174 @@
+// Package client 是fabric-client REST接口的Go客户端，自动填写时间戳并计算签名
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 与服务端一致的错误码，完整的错误目录见ErrorCatalog
+const (
+	CodeOK                  = 0
+	CodeBatchPartialError   = 20
+	CodeEndorsementMismatch = 23
+	CodeRateLimitedError    = 29
+	CodeMVCCConflictError   = 30
+	CodeSDKTimeoutError     = 31
+	CodePolicyFailureError  = 32
+)
+
+// 调用方标识的请求头，与服务端一致
+const apiKeyHeader = "X-API-Key"
+
+// Client 可以并发使用
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	lang       string
+	now        func() time.Time
+}
+
+type Option func(*Client)
+
+// WithHTTPClient 使用指定的http客户端，默认超时60秒
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = httpClient
+	}
+}
+
+// WithAPIKey 设置调用方标识，用于服务端按调用方限流
+func WithAPIKey(apiKey string) Option {
+	return func(client *Client) {
+		client.apiKey = apiKey
+	}
+}
+
+// WithLang 设置返回提示的语言，en或zh
+func WithLang(lang string) Option {
+	return func(client *Client) {
+		client.lang = lang
+	}
+}
+
+// New 创建客户端，baseURL为服务地址，如http://127.0.0.1:8080
+func New(baseURL string, options ...Option) *Client {
+	client := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		now:        time.Now,
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+// 接口返回的统一结构
+type Result struct {
+	Code    int
+	Message string
+	Data    json.RawMessage
+	Warning string
+	Error   string
+}
+
+// Error 接口返回的错误，Code为错误码，ID为不随语言变化的字符串标识
+type Error struct {
+	StatusCode int
+	Code       int
+	ID         string
+	Message    string
+	Data       json.RawMessage
+	RetryAfter time.Duration // 被限流时服务端建议的等待时长
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("fabric-client: %s (code %d, http %d): %s", e.ID, e.Code, e.StatusCode, e.Message)
+}
+
+// 当前时间戳，签名使用
+func (client *Client) timestamp() int64 {
+	return client.now().Unix()
+}
+
+// 发送请求并解析统一的返回结构，Code不为0时返回*Error，同时返回已解析的结果
+func (client *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}) (*Result, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	if client.lang != "" {
+		query.Set("lang", client.lang)
+	}
+	requestURL := client.baseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if client.apiKey != "" {
+		request.Header.Set(apiKeyHeader, client.apiKey)
+	}
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	result := &Result{}
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return nil, fmt.Errorf("fabric-client: 解析响应失败，HTTP状态码%d: %v", response.StatusCode, err)
+	}
+	if result.Code != CodeOK {
+		apiErr := &Error{StatusCode: response.StatusCode, Code: result.Code, ID: result.Error, Message: result.Message, Data: result.Data}
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return result, apiErr
+	}
+	return result, nil
+}
+
+// 发送请求并将Data解析到data中，data为nil时忽略Data
+func (client *Client) call(ctx context.Context, method string, path string, query url.Values, body interface{}, data interface{}) (*Result, error) {
+	result, err := client.do(ctx, method, path, query, body)
+	if err != nil {
+		return result, err
+	}
+	return result, decodeData(result, data)
+}
+
+func decodeData(result *Result, data interface{}) error {
+	if data == nil || len(result.Data) == 0 || string(result.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(result.Data, data); err != nil {
+		return fmt.Errorf("fabric-client: 解析Data失败: %v", err)
+	}
+	return nil
+}
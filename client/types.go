@@ -0,0 +1,278 @@
+package client
+
+import "encoding/json"
+
+// 请求类型与服务端相同，但不包含Timestamp和Sign，由客户端在发送时填写
+
+// 创建或加入通道的请求
+type ChannelRequest struct {
+	ChannelID string
+	OrgName   string
+}
+
+// 安装、实例化和升级链码的请求
+type CCRequest struct {
+	ChannelID        string // 安装链码时不用传
+	OrgName          string
+	ChaincodeID      string
+	ChaincodeVersion string
+	ChaincodePath    string
+	Args             []string
+}
+
+// 执行和查询链码的请求，Timeout和RetryBackoff的单位为毫秒
+type ChaincodeRequest struct {
+	ChannelID        string
+	OrgName          string
+	UserName         string
+	ChaincodeID      string
+	Fcn              string
+	Args             []string
+	EventFilter      string   //查询链码不用传
+	EventCallbackUrl string   //查询链码不用传
+	RequestID        string   //幂等请求ID，查询链码不用传
+	Async            bool     //由ExecAsync设置
+	DryRun           bool     //由Simulate设置
+	TargetPeers      []string //指定背书节点，节点名称或URL
+	Timeout          int64
+	RetryAttempts    int
+	RetryBackoff     int64
+}
+
+// 账本对账请求，EndBlock为0时对账到最新区块
+type ReconcileRequest struct {
+	ChannelID  string
+	OrgName    string
+	UserName   string
+	StartBlock uint64
+	EndBlock   uint64
+	Repair     bool
+}
+
+// 区块过滤条件，MinNumber和MaxNumber为nil时不限制，TimeFormat为空时使用unix
+type BlockFilter struct {
+	StartDate  string
+	EndDate    string
+	ChannelID  string
+	TxIDPrefix string
+	MinNumber  *int64
+	MaxNumber  *int64
+	Timezone   string
+	TimeFormat string
+}
+
+// 区块交易分页查询条件
+type BlockQuery struct {
+	PageNumber int
+	PageSize   int
+	SortName   string
+	SortOrder  string
+	BlockFilter
+}
+
+// 区块交易游标分页查询条件，SortOrder为空时使用asc
+type BlockCursorQuery struct {
+	PageSize  int
+	Cursor    string
+	SortOrder string
+	BlockFilter
+}
+
+// 交易历史查询条件，StartTime和EndTime为unix秒，0为不限制
+type TxHistoryQuery struct {
+	PageNumber  int
+	PageSize    int
+	ChaincodeID string
+	Fcn         string
+	OrgName     string
+	UserName    string
+	Status      string
+	StartTime   int64
+	EndTime     int64
+	Timezone    string
+	TimeFormat  string
+}
+
+// 交易状态
+const (
+	TxPending   = "pending"
+	TxCommitted = "committed"
+	TxInvalid   = "invalid"
+	TxUnknown   = "unknown" // 未收到提交事件且账本中查不到，交易仍可能上链
+)
+
+// 同步执行和查询链码的结果
+type TxResponse struct {
+	TransactionID    string
+	TxValidationCode int32
+	ChaincodeStatus  int32
+	Payload          []byte
+	Warning          string `json:"-"` // 交易已上链但有需要注意的情况，如索引延后
+}
+
+// 异步交易的状态
+type TxState struct {
+	TxID           string
+	ChannelID      string
+	ChaincodeID    string
+	Status         string
+	ValidationCode string
+	BlockNumber    uint64
+	Payload        []byte
+	Error          string
+	SubmittedAt    int64
+	UpdatedAt      int64
+}
+
+// 模拟执行的结果
+type SimulationResult struct {
+	TxID         string
+	Consistent   bool
+	Endorsements []*EndorsementResult
+}
+
+type EndorsementResult struct {
+	Endorser        string
+	Status          int32
+	ChaincodeStatus int32
+	Message         string
+	Payload         []byte
+	ReadWriteSets   []*NsReadWriteSet
+}
+
+type NsReadWriteSet struct {
+	Namespace string
+	Reads     []*KVRead
+	Writes    []*KVWrite
+}
+
+type KVRead struct {
+	Key      string
+	BlockNum uint64
+	TxNum    uint64
+}
+
+type KVWrite struct {
+	Key      string
+	IsDelete bool
+	Value    []byte
+}
+
+// 批量执行的结果
+type BatchResult struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	Items     []BatchItemResult
+}
+
+// 批量执行中单个请求的结果，成功时Data为TxResponse
+type BatchItemResult struct {
+	Index   int
+	Code    int
+	Message string
+	Data    json.RawMessage
+	Warning string
+	Error   string
+}
+
+// 区块交易索引
+type Block struct {
+	Id           int    `json:"id"`
+	Number       uint64 `json:"number"`
+	PreviousHash string `json:"previous_hash"`
+	TxId         string `json:"tx_id"`
+	Timestamp    int64  `json:"timestamp"`
+	ChannelId    string `json:"channel_id"`
+	Creator      string `json:"creator"`
+	TimestampNs  int64  `json:"timestamp_ns"`
+	Time         string `json:"time,omitempty"`
+}
+
+type BlockPage struct {
+	Total int64    `json:"total"`
+	Rows  []*Block `json:"rows"`
+}
+
+// NextCursor为空时没有下一页
+type BlockCursorPage struct {
+	Rows       []*Block `json:"rows"`
+	NextCursor string   `json:"nextCursor"`
+}
+
+// 交易历史
+type TxHistory struct {
+	Id             int64  `json:"id"`
+	TxId           string `json:"tx_id"`
+	ChannelId      string `json:"channel_id"`
+	ChaincodeId    string `json:"chaincode_id"`
+	Fcn            string `json:"fcn"`
+	Args           string `json:"args"`
+	OrgName        string `json:"org_name"`
+	UserName       string `json:"user_name"`
+	Creator        string `json:"creator"`
+	Status         string `json:"status"`
+	ValidationCode string `json:"validation_code"`
+	BlockNumber    uint64 `json:"block_number"`
+	BlockHash      string `json:"block_hash"`
+	PreviousHash   string `json:"previous_hash"`
+	Payload        []byte `json:"payload"`
+	Timestamp      int64  `json:"timestamp"`
+	TimestampNs    int64  `json:"timestamp_ns"`
+	Time           string `json:"time,omitempty"`
+}
+
+type TxHistoryPage struct {
+	Total int64        `json:"total"`
+	Rows  []*TxHistory `json:"rows"`
+}
+
+// 对账报告
+type ReconcileReport struct {
+	ChannelID     string            `json:"channelId"`
+	StartBlock    uint64            `json:"startBlock"`
+	EndBlock      uint64            `json:"endBlock"`
+	BlocksScanned int               `json:"blocksScanned"`
+	TxScanned     int               `json:"txScanned"`
+	Repair        bool              `json:"repair"`
+	Repaired      int               `json:"repaired"`
+	Issues        []*ReconcileIssue `json:"issues"`
+	StartedAt     int64             `json:"startedAt"`
+	FinishedAt    int64             `json:"finishedAt"`
+}
+
+type ReconcileIssue struct {
+	Type     string `json:"type"`
+	TxID     string `json:"txId"`
+	Indexed  *Block `json:"indexed,omitempty"`
+	Expected *Block `json:"expected,omitempty"`
+	Repaired bool   `json:"repaired"`
+	Error    string `json:"error,omitempty"`
+}
+
+// 组织的节点诊断信息
+type OrgDiagnostics struct {
+	OrgName string             `json:"orgName"`
+	Nodes   []*NodeDiagnostics `json:"nodes"`
+	Error   string             `json:"error,omitempty"`
+}
+
+type NodeDiagnostics struct {
+	Type          string            `json:"type"`
+	URL           string            `json:"url"`
+	Reachable     bool              `json:"reachable"`
+	LatencyMs     int64             `json:"latencyMs"`
+	TLS           bool              `json:"tls"`
+	TLSCertExpiry int64             `json:"tlsCertExpiry,omitempty"`
+	TLSCAExpiry   int64             `json:"tlsCaExpiry,omitempty"`
+	Channels      []string          `json:"channels,omitempty"`
+	BlockHeights  map[string]uint64 `json:"blockHeights,omitempty"`
+	Errors        []string          `json:"errors,omitempty"`
+}
+
+// 错误目录中的一项
+type ErrorInfo struct {
+	Code   int    `json:"code"`
+	ID     string `json:"id"`
+	Status int    `json:"status"`
+}
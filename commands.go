@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fabric-client/db"
+	"fabric-client/db/migrate"
+	"fabric-client/sdkInit"
+	"fabric-client/service"
+	"flag"
+	"fmt"
+)
+
+// 命令行子命令，执行完成后退出，不启动服务
+func runCommand(command string, args []string) error {
+	switch command {
+	case "migrate":
+		return runMigrate(args)
+	case "reconcile":
+		return runReconcile(args)
+	default:
+		return fmt.Errorf("未知命令: %s", command)
+	}
+}
+
+// migrate 执行所有未执行的迁移，migrate status 查看迁移状态
+func runMigrate(args []string) error {
+	engine := db.MasterEngine()
+	if len(args) > 0 && args[0] == "status" {
+		statuses, err := migrate.Status(engine)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.Applied {
+				applied = "applied"
+			}
+			fmt.Printf("%4d  %-8s  %s\n", status.Version, applied, status.Description)
+		}
+		return nil
+	}
+
+	if err := migrate.Run(engine); err != nil {
+		return err
+	}
+	fmt.Println("数据库迁移完成")
+	return nil
+}
+
+// reconcile -channel mychannel -org Org1 -user User1 [-start 0] [-end 0] [-repair]
+// 对账指定通道的区块范围，输出json格式的报告
+func runReconcile(args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	channelID := flags.String("channel", "", "通道ID")
+	orgName := flags.String("org", "", "组织名称")
+	userName := flags.String("user", "", "用户名称")
+	start := flags.Uint64("start", 0, "起始区块号")
+	end := flags.Uint64("end", 0, "结束区块号，为0时对账到最新区块")
+	repair := flags.Bool("repair", false, "按账本修复区块索引")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *channelID == "" || *orgName == "" || *userName == "" {
+		return fmt.Errorf("channel、org、user参数不能为空")
+	}
+
+	clientMap, err := sdkInit.InitClientMap()
+	defer sdkInit.CloseClientMap(clientMap)
+	if err != nil {
+		return err
+	}
+	service.ClientMap = clientMap
+
+	setup, err := service.NewLedgerSetup(*channelID, *orgName, *userName)
+	if err != nil {
+		return err
+	}
+	report, err := setup.Reconcile(*start, *end, *repair)
+	if err != nil {
+		return err
+	}
+
+	reportJson, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(reportJson))
+	return nil
+}
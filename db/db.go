@@ -3,13 +3,20 @@ package db
 import (
 	"fmt"
 	"github.com/go-xorm/xorm"
-	"github.com/kataras/golog"
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 	"fabric-client/inits/parse"
+	"fabric-client/logger"
+	"net"
+	"net/url"
+	"strconv"
 	"sync"
 	"time"
 )
 
+var log = logger.Named("db")
+
 var (
 	masterEngine *xorm.Engine
 	slaveEngine  *xorm.Engine
@@ -32,7 +39,7 @@ func MasterEngine() *xorm.Engine {
 
 	engine, err := xorm.NewEngine(masterDB.Dialect, GetDBConnURL(&masterDB))
 	if err != nil {
-		golog.Fatalf("连接主数据库失败:%s", err)
+		log.Fatalf("连接主数据库失败:%s", err)
 		return nil
 	}
 	settings(engine, &masterDB)
@@ -48,23 +55,26 @@ func SlaveEngine() *xorm.Engine {
 	lock.Lock()
 	defer lock.Unlock()
 
-	masterDB := parse.DB.MasterDB
+	if slaveEngine != nil {
+		return slaveEngine
+	}
 
-	engine, err := xorm.NewEngine(masterDB.Dialect, GetDBConnURL(&masterDB))
+	slaveDB := parse.DB.Slave
+
+	engine, err := xorm.NewEngine(slaveDB.Dialect, GetDBConnURL(&slaveDB))
 	if err != nil {
-		golog.Fatalf("连接从数据库失败:%s", err)
+		log.Fatalf("连接从数据库失败:%s", err)
 		return nil
 	}
-	settings(engine, &masterDB)
+	settings(engine, &slaveDB)
 	slaveEngine = engine
 	return slaveEngine
 }
 
-
 func settings(engine *xorm.Engine, info *parse.DBYamlConfig) {
 	engine.ShowSQL(info.ShowSql)
-	localTime, _ := time.LoadLocation("Asia/Shanghai")
-	engine.SetTZLocation(localTime)
+	engine.SetTZLocation(parse.DB.Location())
+	engine.SetTZDatabase(time.UTC)
 	if info.MaxIdleConns > 0 {
 		engine.SetMaxIdleConns(info.MaxIdleConns)
 	}
@@ -73,7 +83,39 @@ func settings(engine *xorm.Engine, info *parse.DBYamlConfig) {
 	}
 }
 
+// Close 停止从库健康检查并关闭所有数据库连接，退出前调用
+func Close() {
+	healthCheckStopOnce.Do(func() { close(healthCheckStop) })
+
+	lock.Lock()
+	defer lock.Unlock()
+	engines := []*xorm.Engine{masterEngine, slaveEngine}
+	for _, r := range replicas {
+		engines = append(engines, r.engine)
+	}
+	for _, engine := range engines {
+		if engine == nil {
+			continue
+		}
+		if err := engine.Close(); err != nil {
+			log.Warnw("关闭数据库连接失败", "error", err)
+		}
+	}
+}
+
+// GetDBConnURL 根据数据库类型生成连接串，支持mysql、postgres和sqlite3
 func GetDBConnURL(info *parse.DBYamlConfig) (url string) {
+	switch info.Dialect {
+	case "postgres":
+		return getPostgresConnURL(info)
+	case "sqlite3":
+		return getSqliteConnURL(info)
+	default:
+		return getMysqlConnURL(info)
+	}
+}
+
+func getMysqlConnURL(info *parse.DBYamlConfig) (url string) {
 	url = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s",
 		info.User,
 		info.Password,
@@ -83,3 +125,26 @@ func GetDBConnURL(info *parse.DBYamlConfig) (url string) {
 		info.Charset)
 	return url
 }
+
+// 生成postgres://格式的连接串，用户名、密码和库名经过URL编码，可以包含空格、引号等字符。
+// xorm按空格拆分key=value格式的连接串，不支持其中带引号的值
+func getPostgresConnURL(info *parse.DBYamlConfig) string {
+	sslMode := info.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	connURL := &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(info.User, info.Password),
+		Host:     net.JoinHostPort(info.Host, strconv.Itoa(info.Port)),
+		Path:     "/" + info.Database,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+	return connURL.String()
+}
+
+// sqlite3的database为数据库文件路径
+func getSqliteConnURL(info *parse.DBYamlConfig) (url string) {
+	url = fmt.Sprintf("file:%s?_busy_timeout=5000", info.Database)
+	return url
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"fabric-client/inits/parse"
+	"testing"
+
+	"github.com/go-xorm/xorm"
+	"github.com/lib/pq"
+)
+
+func TestPostgresConnURL(t *testing.T) {
+	info := &parse.DBYamlConfig{Dialect: "postgres", Host: "127.0.0.1", Port: 5432, User: "fabric user", Password: `p a's\s@/?#:`, Database: "fabric"}
+	connURL := GetDBConnURL(info)
+
+	// lib/pq把URL转换为key=value格式时按libpq的规则转义，用户名和密码与配置一致
+	opts, err := pq.ParseURL(connURL)
+	if err != nil {
+		t.Fatalf("pq解析连接串%s失败: %v", connURL, err)
+	}
+	expected := `dbname=fabric host=127.0.0.1 password=p\ a\'s\\s@/?#: port=5432 sslmode=disable user=fabric\ user`
+	if opts != expected {
+		t.Errorf("pq解析出的连接参数为%s，应为%s", opts, expected)
+	}
+
+	engine, err := xorm.NewEngine("postgres", connURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+	if dbName := engine.Dialect().URI().DbName; dbName != info.Database {
+		t.Errorf("xorm解析出的库名为%q，应为%q", dbName, info.Database)
+	}
+}
@@ -0,0 +1,123 @@
+package migrate
+
+import (
+	"fabric-client/logger"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-xorm/xorm"
+)
+
+var log = logger.Named("migrate")
+
+// 数据库版本迁移，Up在事务中执行，mysql中DDL不能回滚，Up中的每一步需要可以重复执行
+type Migration struct {
+	Version     int64
+	Description string
+	Up          func(session *xorm.Session, dialect string) error
+}
+
+// 已执行的迁移记录
+type SchemaMigration struct {
+	Version     int64  `json:"version" xorm:"pk BIGINT(20) notnull"`
+	Description string `json:"description" xorm:"varchar(255) notnull"`
+	AppliedAt   int64  `json:"applied_at" xorm:"bigInt notnull"`
+}
+
+// 迁移的执行状态
+type MigrationStatus struct {
+	Version     int64
+	Description string
+	Applied     bool
+	AppliedAt   int64
+}
+
+// Run 按版本顺序执行所有未执行的迁移
+func Run(engine *xorm.Engine) error {
+	applied, err := appliedMigrations(engine)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range sortedMigrations() {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		log.Infow("执行数据库迁移", "version", migration.Version, "description", migration.Description)
+		if err := apply(engine, migration); err != nil {
+			return fmt.Errorf("执行数据库迁移%d失败: %v", migration.Version, err)
+		}
+	}
+	return nil
+}
+
+// Status 返回所有迁移的执行状态
+func Status(engine *xorm.Engine) ([]*MigrationStatus, error) {
+	applied, err := appliedMigrations(engine)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*MigrationStatus, 0, len(migrations))
+	for _, migration := range sortedMigrations() {
+		status := &MigrationStatus{Version: migration.Version, Description: migration.Description}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func apply(engine *xorm.Engine, migration *Migration) error {
+	session := engine.NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+	if err := migration.Up(session, engine.DriverName()); err != nil {
+		session.Rollback()
+		return err
+	}
+
+	record := &SchemaMigration{
+		Version:     migration.Version,
+		Description: migration.Description,
+		AppliedAt:   time.Now().Unix(),
+	}
+	if _, err := session.Insert(record); err != nil {
+		session.Rollback()
+		return err
+	}
+	return session.Commit()
+}
+
+func appliedMigrations(engine *xorm.Engine) (map[int64]*SchemaMigration, error) {
+	if err := engine.Sync2(new(SchemaMigration)); err != nil {
+		return nil, fmt.Errorf("创建迁移记录表失败: %v", err)
+	}
+
+	records := make([]*SchemaMigration, 0)
+	if err := engine.Find(&records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]*SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+func sortedMigrations() []*Migration {
+	sorted := make([]*Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	return sorted
+}
@@ -0,0 +1,141 @@
+package migrate
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xorm/xorm"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestEngine(t *testing.T) *xorm.Engine {
+	engine, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { engine.Close() })
+	return engine
+}
+
+func TestMigrationVersionsUnique(t *testing.T) {
+	versions := make(map[int64]bool)
+	for _, migration := range migrations {
+		if versions[migration.Version] {
+			t.Errorf("迁移版本%d重复", migration.Version)
+		}
+		versions[migration.Version] = true
+	}
+}
+
+func TestRunFreshDatabase(t *testing.T) {
+	engine := newTestEngine(t)
+	if err := Run(engine); err != nil {
+		t.Fatal(err)
+	}
+	// 再次执行时没有需要执行的迁移
+	if err := Run(engine); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := Status(engine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != len(migrations) {
+		t.Fatalf("迁移状态数量为%d，应为%d", len(statuses), len(migrations))
+	}
+	for _, status := range statuses {
+		if !status.Applied {
+			t.Errorf("迁移%d未执行", status.Version)
+		}
+	}
+
+	statements := []string{
+		"INSERT INTO block_t_x_info (number, previous_hash, tx_id, timestamp, channel_id, creator, timestamp_ns) VALUES (1, 'h', 'tx1', 1, 'c', 'Org1MSP/u', 1000000000)",
+		"INSERT INTO idempotency_record (org_name, request_id, fingerprint, tx_id, tx_validation_code, status, created_at) VALUES ('Org1', 'r1', 'f', 'tx1', 0, 'pending', 1)",
+		"INSERT INTO tx_history (tx_id, channel_id, chaincode_id, fcn, org_name, user_name, status, timestamp, timestamp_ns) VALUES ('tx1', 'c', 'cc', 'f', 'Org1', 'User1', 'committed', 1, 1000000000)",
+	}
+	for _, statement := range statements {
+		if _, err := engine.Exec(statement); err != nil {
+			t.Errorf("%s: %v", statement, err)
+		}
+	}
+}
+
+func TestRunUpgradesExistingData(t *testing.T) {
+	engine := newTestEngine(t)
+	if _, err := appliedMigrations(engine); err != nil {
+		t.Fatal(err)
+	}
+	for _, migration := range sortedMigrations()[:2] {
+		if err := apply(engine, migration); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 版本2中number为字符串
+	if _, err := engine.Exec("INSERT INTO block_t_x_info (number, previous_hash, tx_id, timestamp, channel_id, creator) VALUES ('42', 'h', 'tx1', 100, 'c', 'Org1MSP/u')"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.Exec("INSERT INTO block_t_x_info (number, previous_hash, tx_id, timestamp, channel_id, creator) VALUES ('9', 'h', 'tx2', 200, 'c', 'Org1MSP/u')"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Run(engine); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := engine.QueryString("SELECT tx_id, timestamp_ns FROM block_t_x_info ORDER BY number")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		txID        string
+		timestampNs string
+	}{
+		{"tx2", "200000000000"},
+		{"tx1", "100000000000"},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("区块数量为%d，应为%d", len(rows), len(expected))
+	}
+	for i, row := range rows {
+		if row["tx_id"] != expected[i].txID || row["timestamp_ns"] != expected[i].timestampNs {
+			t.Errorf("第%d行为%v，应为%+v，number需要按整数排序", i, row, expected[i])
+		}
+	}
+}
+
+// 模拟迁移执行了一部分后重新执行，每个迁移的Up都需要可以重复执行
+func TestMigrationsRerunnable(t *testing.T) {
+	engine := newTestEngine(t)
+	if err := Run(engine); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.Exec("INSERT INTO block_t_x_info (number, previous_hash, tx_id, timestamp, channel_id, creator, timestamp_ns) VALUES (7, 'h', 'tx1', 1, 'c', 'Org1MSP/u', 5)"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, migration := range sortedMigrations() {
+		session := engine.NewSession()
+		if err := session.Begin(); err != nil {
+			t.Fatal(err)
+		}
+		if err := migration.Up(session, engine.DriverName()); err != nil {
+			session.Rollback()
+			session.Close()
+			t.Fatalf("重新执行迁移%d失败: %v", migration.Version, err)
+		}
+		if err := session.Commit(); err != nil {
+			t.Fatal(err)
+		}
+		session.Close()
+	}
+
+	rows, err := engine.QueryString("SELECT number, timestamp_ns FROM block_t_x_info WHERE tx_id = 'tx1'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0]["number"] != "7" || rows[0]["timestamp_ns"] != "5" {
+		t.Errorf("重新执行迁移后数据为%v", rows)
+	}
+}
@@ -0,0 +1,283 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-xorm/xorm"
+)
+
+// 所有迁移，新增迁移只能追加，已发布的迁移不能修改。
+// 迁移中使用各版本的表结构快照，不依赖models中的当前结构。
+// mysql的DDL会隐式提交事务，迁移失败时可能已经执行了一部分，每一步都需要可以重复执行。
+var migrations = []*Migration{
+	{
+		Version:     1,
+		Description: "create block_t_x_info",
+		Up: func(session *xorm.Session, dialect string) error {
+			return session.Sync2(new(blockTXInfoV1))
+		},
+	},
+	{
+		Version:     2,
+		Description: "add creator to block_t_x_info",
+		Up: func(session *xorm.Session, dialect string) error {
+			return session.Sync2(new(blockTXInfoV2))
+		},
+	},
+	{
+		Version:     3,
+		Description: "change block_t_x_info.number to bigint and add indexes",
+		Up: func(session *xorm.Session, dialect string) error {
+			// mysql中修改列会隐式提交事务，失败后重新执行时列可能已经修改
+			integer, err := isIntegerColumn(session, dialect, "block_t_x_info", "number")
+			if err != nil {
+				return err
+			}
+			if !integer {
+				switch dialect {
+				case "mysql":
+					if _, err := session.Exec("ALTER TABLE block_t_x_info MODIFY number BIGINT(20) NOT NULL"); err != nil {
+						return err
+					}
+				case "postgres":
+					if _, err := session.Exec("ALTER TABLE block_t_x_info ALTER COLUMN number TYPE BIGINT USING number::bigint"); err != nil {
+						return err
+					}
+				case "sqlite3":
+					// sqlite不支持修改列类型，需要重建表
+					return rebuildSqliteTable(session, "block_t_x_info", new(blockTXInfoV3),
+						"id, number, previous_hash, tx_id, timestamp, channel_id, creator",
+						"id, CAST(number AS INTEGER), previous_hash, tx_id, timestamp, channel_id, creator")
+				}
+			}
+			return session.Sync2(new(blockTXInfoV3))
+		},
+	},
+	{
+		Version:     4,
+		Description: "create idempotency_record",
+		Up: func(session *xorm.Session, dialect string) error {
+			return session.Sync2(new(idempotencyRecordV1))
+		},
+	},
+	{
+		Version:     5,
+		Description: "create tx_history",
+		Up: func(session *xorm.Session, dialect string) error {
+			return session.Sync2(new(txHistoryV1))
+		},
+	},
+	{
+		Version:     6,
+		Description: "add full-precision utc timestamp_ns",
+		Up: func(session *xorm.Session, dialect string) error {
+			if err := session.Sync2(new(blockTXInfoV4), new(txHistoryV2)); err != nil {
+				return err
+			}
+			for _, table := range []string{"block_t_x_info", "tx_history"} {
+				if _, err := session.Exec("UPDATE " + table + " SET timestamp_ns = timestamp * 1000000000 WHERE timestamp_ns = 0"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     7,
+		Description: "add status to idempotency_record",
+		Up: func(session *xorm.Session, dialect string) error {
+			return session.Sync2(new(idempotencyRecordV2))
+		},
+	},
+	{
+		Version:     8,
+		Description: "add fingerprint to idempotency_record",
+		Up: func(session *xorm.Session, dialect string) error {
+			return session.Sync2(new(idempotencyRecordV3))
+		},
+	},
+}
+
+// 查询列的类型是否为整数，用于判断修改列类型的步骤是否已经执行
+func isIntegerColumn(session *xorm.Session, dialect string, tableName string, columnName string) (bool, error) {
+	var rows []map[string]string
+	var err error
+	switch dialect {
+	case "mysql":
+		rows, err = session.QueryString("SELECT DATA_TYPE AS type FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = '" + tableName + "' AND COLUMN_NAME = '" + columnName + "'")
+	case "postgres":
+		rows, err = session.QueryString("SELECT data_type AS type FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = '" + tableName + "' AND column_name = '" + columnName + "'")
+	case "sqlite3":
+		var columns []map[string]string
+		columns, err = session.QueryString("PRAGMA table_info(" + tableName + ")")
+		for _, column := range columns {
+			if column["name"] == columnName {
+				rows = append(rows, column)
+			}
+		}
+	default:
+		return false, fmt.Errorf("不支持的数据库类型: %s", dialect)
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(rows) == 0 {
+		return false, fmt.Errorf("表%s中不存在列%s", tableName, columnName)
+	}
+	return strings.Contains(strings.ToLower(rows[0]["type"]), "int"), nil
+}
+
+// 重建sqlite表：重命名旧表，按新结构建表和索引，复制数据后删除旧表
+func rebuildSqliteTable(session *xorm.Session, tableName string, bean interface{}, columns string, selectColumns string) error {
+	oldTableName := tableName + "_old"
+	if _, err := session.Exec("ALTER TABLE " + tableName + " RENAME TO " + oldTableName); err != nil {
+		return err
+	}
+	if err := session.CreateTable(bean); err != nil {
+		return err
+	}
+	if err := session.CreateIndexes(bean); err != nil {
+		return err
+	}
+	if err := session.CreateUniques(bean); err != nil {
+		return err
+	}
+	if _, err := session.Exec("INSERT INTO " + tableName + " (" + columns + ") SELECT " + selectColumns + " FROM " + oldTableName); err != nil {
+		return err
+	}
+	_, err := session.Exec("DROP TABLE " + oldTableName)
+	return err
+}
+
+// 以下为各版本的表结构快照
+
+type blockTXInfoV1 struct {
+	Id           int    `xorm:"pk autoincr INT(10) notnull"`
+	Number       uint64 `xorm:"varchar(255) notnull"`
+	PreviousHash string `xorm:"varchar(255) notnull"`
+	TxId         string `xorm:"varchar(255) notnull"`
+	Timestamp    int64  `xorm:"bigInt notnull"`
+	ChannelId    string `xorm:"varchar(255) notnull"`
+}
+
+func (blockTXInfoV1) TableName() string { return "block_t_x_info" }
+
+type blockTXInfoV2 struct {
+	Id           int    `xorm:"pk autoincr INT(10) notnull"`
+	Number       uint64 `xorm:"varchar(255) notnull"`
+	PreviousHash string `xorm:"varchar(255) notnull"`
+	TxId         string `xorm:"varchar(255) notnull"`
+	Timestamp    int64  `xorm:"bigInt notnull"`
+	ChannelId    string `xorm:"varchar(255) notnull"`
+	Creator      string `xorm:"varchar(255)"`
+}
+
+func (blockTXInfoV2) TableName() string { return "block_t_x_info" }
+
+type blockTXInfoV3 struct {
+	Id           int    `xorm:"pk autoincr INT(10) notnull"`
+	Number       uint64 `xorm:"BIGINT(20) notnull index"`
+	PreviousHash string `xorm:"varchar(255) notnull"`
+	TxId         string `xorm:"varchar(255) notnull index"`
+	Timestamp    int64  `xorm:"bigInt notnull index"`
+	ChannelId    string `xorm:"varchar(255) notnull index"`
+	Creator      string `xorm:"varchar(255)"`
+}
+
+func (blockTXInfoV3) TableName() string { return "block_t_x_info" }
+
+type idempotencyRecordV1 struct {
+	Id               int64  `xorm:"pk autoincr BIGINT(20) notnull"`
+	OrgName          string `xorm:"varchar(255) notnull unique(org_request)"`
+	RequestId        string `xorm:"varchar(128) notnull unique(org_request)"`
+	TxId             string `xorm:"varchar(255) notnull"`
+	TxValidationCode int32  `xorm:"INT(10) notnull"`
+	Payload          []byte `xorm:"blob"`
+	CreatedAt        int64  `xorm:"bigInt notnull index"`
+}
+
+func (idempotencyRecordV1) TableName() string { return "idempotency_record" }
+
+type txHistoryV1 struct {
+	Id             int64  `xorm:"pk autoincr BIGINT(20) notnull"`
+	TxId           string `xorm:"varchar(255) notnull unique"`
+	ChannelId      string `xorm:"varchar(255) notnull"`
+	ChaincodeId    string `xorm:"varchar(255) notnull index(chaincode)"`
+	Fcn            string `xorm:"varchar(255) notnull index(chaincode)"`
+	Args           string `xorm:"text"`
+	OrgName        string `xorm:"varchar(255) notnull index(caller)"`
+	UserName       string `xorm:"varchar(255) notnull index(caller)"`
+	Creator        string `xorm:"varchar(255)"`
+	Status         string `xorm:"varchar(32) notnull index"`
+	ValidationCode string `xorm:"varchar(64)"`
+	BlockNumber    uint64 `xorm:"BIGINT(20)"`
+	BlockHash      string `xorm:"varchar(255)"`
+	PreviousHash   string `xorm:"varchar(255)"`
+	Payload        []byte `xorm:"blob"`
+	Timestamp      int64  `xorm:"bigInt notnull index"`
+}
+
+func (txHistoryV1) TableName() string { return "tx_history" }
+
+type blockTXInfoV4 struct {
+	Id           int    `xorm:"pk autoincr INT(10) notnull"`
+	Number       uint64 `xorm:"BIGINT(20) notnull index"`
+	PreviousHash string `xorm:"varchar(255) notnull"`
+	TxId         string `xorm:"varchar(255) notnull index"`
+	Timestamp    int64  `xorm:"bigInt notnull index"`
+	ChannelId    string `xorm:"varchar(255) notnull index"`
+	Creator      string `xorm:"varchar(255)"`
+	TimestampNs  int64  `xorm:"BIGINT(20) notnull default 0"`
+}
+
+func (blockTXInfoV4) TableName() string { return "block_t_x_info" }
+
+type txHistoryV2 struct {
+	Id             int64  `xorm:"pk autoincr BIGINT(20) notnull"`
+	TxId           string `xorm:"varchar(255) notnull unique"`
+	ChannelId      string `xorm:"varchar(255) notnull"`
+	ChaincodeId    string `xorm:"varchar(255) notnull index(chaincode)"`
+	Fcn            string `xorm:"varchar(255) notnull index(chaincode)"`
+	Args           string `xorm:"text"`
+	OrgName        string `xorm:"varchar(255) notnull index(caller)"`
+	UserName       string `xorm:"varchar(255) notnull index(caller)"`
+	Creator        string `xorm:"varchar(255)"`
+	Status         string `xorm:"varchar(32) notnull index"`
+	ValidationCode string `xorm:"varchar(64)"`
+	BlockNumber    uint64 `xorm:"BIGINT(20)"`
+	BlockHash      string `xorm:"varchar(255)"`
+	PreviousHash   string `xorm:"varchar(255)"`
+	Payload        []byte `xorm:"blob"`
+	Timestamp      int64  `xorm:"bigInt notnull index"`
+	TimestampNs    int64  `xorm:"BIGINT(20) notnull default 0"`
+}
+
+func (txHistoryV2) TableName() string { return "tx_history" }
+
+type idempotencyRecordV2 struct {
+	Id               int64  `xorm:"pk autoincr BIGINT(20) notnull"`
+	OrgName          string `xorm:"varchar(255) notnull unique(org_request)"`
+	RequestId        string `xorm:"varchar(128) notnull unique(org_request)"`
+	TxId             string `xorm:"varchar(255) notnull"`
+	TxValidationCode int32  `xorm:"INT(10) notnull"`
+	Status           string `xorm:"varchar(32) notnull default 'committed'"`
+	Payload          []byte `xorm:"blob"`
+	CreatedAt        int64  `xorm:"bigInt notnull index"`
+}
+
+func (idempotencyRecordV2) TableName() string { return "idempotency_record" }
+
+type idempotencyRecordV3 struct {
+	Id               int64  `xorm:"pk autoincr BIGINT(20) notnull"`
+	OrgName          string `xorm:"varchar(255) notnull unique(org_request)"`
+	RequestId        string `xorm:"varchar(128) notnull unique(org_request)"`
+	Fingerprint      string `xorm:"varchar(64) notnull default ''"`
+	TxId             string `xorm:"varchar(255) notnull"`
+	TxValidationCode int32  `xorm:"INT(10) notnull"`
+	Status           string `xorm:"varchar(32) notnull default 'committed'"`
+	Payload          []byte `xorm:"blob"`
+	CreatedAt        int64  `xorm:"bigInt notnull index"`
+}
+
+func (idempotencyRecordV3) TableName() string { return "idempotency_record" }
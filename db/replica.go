@@ -0,0 +1,110 @@
+package db
+
+import (
+	"fabric-client/inits/parse"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-xorm/xorm"
+)
+
+// 从库健康检查的默认间隔
+const defaultHealthCheckInterval = 10 * time.Second
+
+// 只读从库
+type replica struct {
+	name    string
+	engine  *xorm.Engine
+	healthy int32 // 1为健康
+}
+
+var (
+	replicas     []*replica
+	replicasOnce sync.Once
+	replicaIndex uint32
+
+	healthCheckStop     = make(chan struct{})
+	healthCheckStopOnce sync.Once
+)
+
+// ReadEngine 轮询返回一个健康的从库，没有可用从库时返回主库
+func ReadEngine() *xorm.Engine {
+	replicasOnce.Do(initReplicas)
+
+	count := len(replicas)
+	for i := 0; i < count; i++ {
+		r := replicas[int(atomic.AddUint32(&replicaIndex, 1))%count]
+		if atomic.LoadInt32(&r.healthy) == 1 {
+			return r.engine
+		}
+	}
+	return MasterEngine()
+}
+
+// StartHealthCheck 定期检查从库连接，不健康的从库不再用于读取
+func StartHealthCheck() {
+	replicasOnce.Do(initReplicas)
+	if len(replicas) == 0 {
+		return
+	}
+
+	interval := parse.DB.HealthCheckInterval
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				for _, r := range replicas {
+					checkReplica(r)
+				}
+			case <-healthCheckStop:
+				return
+			}
+		}
+	}()
+}
+
+// 从库配置，未配置slaves时使用slave
+func replicaConfigs() []parse.DBYamlConfig {
+	if len(parse.DB.Slaves) > 0 {
+		return parse.DB.Slaves
+	}
+	if parse.DB.Slave.Host != "" {
+		return []parse.DBYamlConfig{parse.DB.Slave}
+	}
+	return nil
+}
+
+func initReplicas() {
+	for _, config := range replicaConfigs() {
+		info := config
+		engine, err := xorm.NewEngine(info.Dialect, GetDBConnURL(&info))
+		if err != nil {
+			log.Errorw("连接从数据库失败", "replica", info.Host, "error", err)
+			continue
+		}
+		settings(engine, &info)
+
+		r := &replica{name: info.Host, engine: engine}
+		checkReplica(r)
+		replicas = append(replicas, r)
+	}
+}
+
+func checkReplica(r *replica) {
+	if err := r.engine.Ping(); err != nil {
+		if atomic.SwapInt32(&r.healthy, 0) == 1 {
+			log.Warnw("从数据库不可用，读取切换到其他数据库", "replica", r.name, "error", err)
+		}
+		return
+	}
+	if atomic.SwapInt32(&r.healthy, 1) == 0 {
+		log.Infow("从数据库可用", "replica", r.name)
+	}
+}
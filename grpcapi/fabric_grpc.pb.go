@@ -0,0 +1,475 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// FabricClient is the client API for Fabric service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FabricClient interface {
+	// 签名原串：orgName=&channelID=&timestamp=
+	CreateChannel(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*StatusReply, error)
+	// 签名原串：orgName=&channelID=&timestamp=
+	JoinChannel(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*StatusReply, error)
+	// 签名原串：orgName=&chaincodeID=&chaincodeVersion=&chaincodePath=&timestamp=
+	InstallChaincode(ctx context.Context, in *CCRequest, opts ...grpc.CallOption) (*StatusReply, error)
+	// 签名原串：channelID=&orgName=&chaincodeID=&chaincodeVersion=&chaincodePath=&timestamp=
+	InstantiateChaincode(ctx context.Context, in *CCRequest, opts ...grpc.CallOption) (*StatusReply, error)
+	// 签名原串：channelID=&orgName=&chaincodeID=&chaincodeVersion=&chaincodePath=&timestamp=
+	UpgradeChaincode(ctx context.Context, in *CCRequest, opts ...grpc.CallOption) (*StatusReply, error)
+	// 签名原串与POST /api/chaincode/exec相同，按async和dry_run返回response、state或simulation
+	Exec(ctx context.Context, in *ChaincodeRequest, opts ...grpc.CallOption) (*ChaincodeReply, error)
+	// 签名原串与POST /api/chaincode/query相同，返回response
+	Query(ctx context.Context, in *ChaincodeRequest, opts ...grpc.CallOption) (*ChaincodeReply, error)
+	// 签名原串与GET /api/pagination/block相同
+	ListBlocks(ctx context.Context, in *BlocksRequest, opts ...grpc.CallOption) (*BlocksReply, error)
+	// 签名原串与GET /api/pagination/block/cursor相同
+	ListBlocksByCursor(ctx context.Context, in *BlocksRequest, opts ...grpc.CallOption) (*BlocksReply, error)
+	// 持续推送链码事件或区块事件，直到调用方取消或服务停止
+	// 签名原串：channelID=&orgName=&userName=&chaincodeID=&eventFilter=[&blocks=true]&timestamp=
+	SubscribeEvents(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (Fabric_SubscribeEventsClient, error)
+}
+
+type fabricClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFabricClient(cc grpc.ClientConnInterface) FabricClient {
+	return &fabricClient{cc}
+}
+
+func (c *fabricClient) CreateChannel(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*StatusReply, error) {
+	out := new(StatusReply)
+	err := c.cc.Invoke(ctx, "/fabricclient.Fabric/CreateChannel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabricClient) JoinChannel(ctx context.Context, in *ChannelRequest, opts ...grpc.CallOption) (*StatusReply, error) {
+	out := new(StatusReply)
+	err := c.cc.Invoke(ctx, "/fabricclient.Fabric/JoinChannel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabricClient) InstallChaincode(ctx context.Context, in *CCRequest, opts ...grpc.CallOption) (*StatusReply, error) {
+	out := new(StatusReply)
+	err := c.cc.Invoke(ctx, "/fabricclient.Fabric/InstallChaincode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabricClient) InstantiateChaincode(ctx context.Context, in *CCRequest, opts ...grpc.CallOption) (*StatusReply, error) {
+	out := new(StatusReply)
+	err := c.cc.Invoke(ctx, "/fabricclient.Fabric/InstantiateChaincode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabricClient) UpgradeChaincode(ctx context.Context, in *CCRequest, opts ...grpc.CallOption) (*StatusReply, error) {
+	out := new(StatusReply)
+	err := c.cc.Invoke(ctx, "/fabricclient.Fabric/UpgradeChaincode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabricClient) Exec(ctx context.Context, in *ChaincodeRequest, opts ...grpc.CallOption) (*ChaincodeReply, error) {
+	out := new(ChaincodeReply)
+	err := c.cc.Invoke(ctx, "/fabricclient.Fabric/Exec", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabricClient) Query(ctx context.Context, in *ChaincodeRequest, opts ...grpc.CallOption) (*ChaincodeReply, error) {
+	out := new(ChaincodeReply)
+	err := c.cc.Invoke(ctx, "/fabricclient.Fabric/Query", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabricClient) ListBlocks(ctx context.Context, in *BlocksRequest, opts ...grpc.CallOption) (*BlocksReply, error) {
+	out := new(BlocksReply)
+	err := c.cc.Invoke(ctx, "/fabricclient.Fabric/ListBlocks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabricClient) ListBlocksByCursor(ctx context.Context, in *BlocksRequest, opts ...grpc.CallOption) (*BlocksReply, error) {
+	out := new(BlocksReply)
+	err := c.cc.Invoke(ctx, "/fabricclient.Fabric/ListBlocksByCursor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fabricClient) SubscribeEvents(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (Fabric_SubscribeEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Fabric_ServiceDesc.Streams[0], "/fabricclient.Fabric/SubscribeEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fabricSubscribeEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Fabric_SubscribeEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type fabricSubscribeEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *fabricSubscribeEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FabricServer is the server API for Fabric service.
+// All implementations must embed UnimplementedFabricServer
+// for forward compatibility
+type FabricServer interface {
+	// 签名原串：orgName=&channelID=&timestamp=
+	CreateChannel(context.Context, *ChannelRequest) (*StatusReply, error)
+	// 签名原串：orgName=&channelID=&timestamp=
+	JoinChannel(context.Context, *ChannelRequest) (*StatusReply, error)
+	// 签名原串：orgName=&chaincodeID=&chaincodeVersion=&chaincodePath=&timestamp=
+	InstallChaincode(context.Context, *CCRequest) (*StatusReply, error)
+	// 签名原串：channelID=&orgName=&chaincodeID=&chaincodeVersion=&chaincodePath=&timestamp=
+	InstantiateChaincode(context.Context, *CCRequest) (*StatusReply, error)
+	// 签名原串：channelID=&orgName=&chaincodeID=&chaincodeVersion=&chaincodePath=&timestamp=
+	UpgradeChaincode(context.Context, *CCRequest) (*StatusReply, error)
+	// 签名原串与POST /api/chaincode/exec相同，按async和dry_run返回response、state或simulation
+	Exec(context.Context, *ChaincodeRequest) (*ChaincodeReply, error)
+	// 签名原串与POST /api/chaincode/query相同，返回response
+	Query(context.Context, *ChaincodeRequest) (*ChaincodeReply, error)
+	// 签名原串与GET /api/pagination/block相同
+	ListBlocks(context.Context, *BlocksRequest) (*BlocksReply, error)
+	// 签名原串与GET /api/pagination/block/cursor相同
+	ListBlocksByCursor(context.Context, *BlocksRequest) (*BlocksReply, error)
+	// 持续推送链码事件或区块事件，直到调用方取消或服务停止
+	// 签名原串：channelID=&orgName=&userName=&chaincodeID=&eventFilter=[&blocks=true]&timestamp=
+	SubscribeEvents(*EventsRequest, Fabric_SubscribeEventsServer) error
+	mustEmbedUnimplementedFabricServer()
+}
+
+// UnimplementedFabricServer must be embedded to have forward compatible implementations.
+type UnimplementedFabricServer struct {
+}
+
+func (UnimplementedFabricServer) CreateChannel(context.Context, *ChannelRequest) (*StatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateChannel not implemented")
+}
+func (UnimplementedFabricServer) JoinChannel(context.Context, *ChannelRequest) (*StatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinChannel not implemented")
+}
+func (UnimplementedFabricServer) InstallChaincode(context.Context, *CCRequest) (*StatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InstallChaincode not implemented")
+}
+func (UnimplementedFabricServer) InstantiateChaincode(context.Context, *CCRequest) (*StatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InstantiateChaincode not implemented")
+}
+func (UnimplementedFabricServer) UpgradeChaincode(context.Context, *CCRequest) (*StatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpgradeChaincode not implemented")
+}
+func (UnimplementedFabricServer) Exec(context.Context, *ChaincodeRequest) (*ChaincodeReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Exec not implemented")
+}
+func (UnimplementedFabricServer) Query(context.Context, *ChaincodeRequest) (*ChaincodeReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedFabricServer) ListBlocks(context.Context, *BlocksRequest) (*BlocksReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBlocks not implemented")
+}
+func (UnimplementedFabricServer) ListBlocksByCursor(context.Context, *BlocksRequest) (*BlocksReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBlocksByCursor not implemented")
+}
+func (UnimplementedFabricServer) SubscribeEvents(*EventsRequest, Fabric_SubscribeEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
+func (UnimplementedFabricServer) mustEmbedUnimplementedFabricServer() {}
+
+// UnsafeFabricServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FabricServer will
+// result in compilation errors.
+type UnsafeFabricServer interface {
+	mustEmbedUnimplementedFabricServer()
+}
+
+func RegisterFabricServer(s grpc.ServiceRegistrar, srv FabricServer) {
+	s.RegisterService(&Fabric_ServiceDesc, srv)
+}
+
+func _Fabric_CreateChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabricServer).CreateChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabricclient.Fabric/CreateChannel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabricServer).CreateChannel(ctx, req.(*ChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric_JoinChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabricServer).JoinChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabricclient.Fabric/JoinChannel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabricServer).JoinChannel(ctx, req.(*ChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric_InstallChaincode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CCRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabricServer).InstallChaincode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabricclient.Fabric/InstallChaincode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabricServer).InstallChaincode(ctx, req.(*CCRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric_InstantiateChaincode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CCRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabricServer).InstantiateChaincode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabricclient.Fabric/InstantiateChaincode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabricServer).InstantiateChaincode(ctx, req.(*CCRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric_UpgradeChaincode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CCRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabricServer).UpgradeChaincode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabricclient.Fabric/UpgradeChaincode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabricServer).UpgradeChaincode(ctx, req.(*CCRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric_Exec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChaincodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabricServer).Exec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabricclient.Fabric/Exec",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabricServer).Exec(ctx, req.(*ChaincodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChaincodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabricServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabricclient.Fabric/Query",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabricServer).Query(ctx, req.(*ChaincodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric_ListBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabricServer).ListBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabricclient.Fabric/ListBlocks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabricServer).ListBlocks(ctx, req.(*BlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric_ListBlocksByCursor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FabricServer).ListBlocksByCursor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fabricclient.Fabric/ListBlocksByCursor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FabricServer).ListBlocksByCursor(ctx, req.(*BlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fabric_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FabricServer).SubscribeEvents(m, &fabricSubscribeEventsServer{stream})
+}
+
+type Fabric_SubscribeEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type fabricSubscribeEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *fabricSubscribeEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Fabric_ServiceDesc is the grpc.ServiceDesc for Fabric service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Fabric_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fabricclient.Fabric",
+	HandlerType: (*FabricServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateChannel",
+			Handler:    _Fabric_CreateChannel_Handler,
+		},
+		{
+			MethodName: "JoinChannel",
+			Handler:    _Fabric_JoinChannel_Handler,
+		},
+		{
+			MethodName: "InstallChaincode",
+			Handler:    _Fabric_InstallChaincode_Handler,
+		},
+		{
+			MethodName: "InstantiateChaincode",
+			Handler:    _Fabric_InstantiateChaincode_Handler,
+		},
+		{
+			MethodName: "UpgradeChaincode",
+			Handler:    _Fabric_UpgradeChaincode_Handler,
+		},
+		{
+			MethodName: "Exec",
+			Handler:    _Fabric_Exec_Handler,
+		},
+		{
+			MethodName: "Query",
+			Handler:    _Fabric_Query_Handler,
+		},
+		{
+			MethodName: "ListBlocks",
+			Handler:    _Fabric_ListBlocks_Handler,
+		},
+		{
+			MethodName: "ListBlocksByCursor",
+			Handler:    _Fabric_ListBlocksByCursor_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       _Fabric_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "fabric.proto",
+}
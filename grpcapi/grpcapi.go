@@ -0,0 +1,4 @@
+// Package grpcapi 是fabric-client的gRPC接口定义，fabric.pb.go和fabric_grpc.pb.go由fabric.proto生成
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative fabric.proto
@@ -0,0 +1,124 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// 返回收到的请求，用于检查消息经过gRPC编解码后不变
+type echoServer struct {
+	UnimplementedFabricServer
+	blocksRequest *BlocksRequest
+}
+
+func (server *echoServer) Exec(ctx context.Context, request *ChaincodeRequest) (*ChaincodeReply, error) {
+	return &ChaincodeReply{
+		Message:  request.Fcn,
+		Response: &TxResponse{TransactionId: request.RequestId, TxValidationCode: 11, Payload: []byte(request.Args[0])},
+		Simulation: &Simulation{TxId: request.RequestId, Endorsements: []*Endorsement{{
+			Endorser:      request.TargetPeers[0],
+			ReadWriteSets: []*NsReadWriteSet{{Namespace: request.ChaincodeId, Writes: []*KVWrite{{Key: "a", IsDelete: true}}}},
+		}}},
+	}, nil
+}
+
+func (server *echoServer) ListBlocks(ctx context.Context, request *BlocksRequest) (*BlocksReply, error) {
+	server.blocksRequest = request
+	return &BlocksReply{Total: 2, Blocks: []*Block{{Number: 1, TxId: "tx1"}, {Number: 2, TxId: "tx2"}}}, nil
+}
+
+func (server *echoServer) SubscribeEvents(request *EventsRequest, stream Fabric_SubscribeEventsServer) error {
+	if err := stream.Send(&Event{Chaincode: &ChaincodeEvent{TxId: "tx1", ChaincodeId: request.ChaincodeId, EventName: request.EventFilter}}); err != nil {
+		return err
+	}
+	return stream.Send(&Event{Block: &BlockEvent{ChannelId: request.ChannelId, Number: 3, Transactions: []*FilteredTransaction{{TxId: "tx1", ValidationCode: "VALID"}}}})
+}
+
+func newTestClient(t *testing.T, server FabricServer) FabricClient {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	RegisterFabricServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewFabricClient(conn)
+}
+
+func TestUnaryRoundTrip(t *testing.T) {
+	server := &echoServer{}
+	client := newTestClient(t, server)
+	ctx := context.Background()
+
+	reply, err := client.Exec(ctx, &ChaincodeRequest{Fcn: "invoke", ChaincodeId: "mycc", Args: []string{"payload"}, RequestId: "req-1",
+		TargetPeers: []string{"peer0"}, Async: true, RetryAttempts: 2, Timestamp: 1600000000, Sign: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &ChaincodeReply{
+		Message:  "invoke",
+		Response: &TxResponse{TransactionId: "req-1", TxValidationCode: 11, Payload: []byte("payload")},
+		Simulation: &Simulation{TxId: "req-1", Endorsements: []*Endorsement{{
+			Endorser:      "peer0",
+			ReadWriteSets: []*NsReadWriteSet{{Namespace: "mycc", Writes: []*KVWrite{{Key: "a", IsDelete: true}}}},
+		}}},
+	}
+	if !proto.Equal(reply, expected) {
+		t.Errorf("Exec返回%v，应为%v", reply, expected)
+	}
+
+	// min_number为0时与未设置区分
+	request := &BlocksRequest{PageNumber: 1, PageSize: 10, MinNumber: wrapperspb.Int64(0), Tz: "UTC", Timestamp: 1600000000}
+	blocks, err := client.ListBlocks(ctx, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(server.blocksRequest, request) || server.blocksRequest.MaxNumber != nil {
+		t.Errorf("服务端收到%v，应为%v", server.blocksRequest, request)
+	}
+	if blocks.Total != 2 || len(blocks.Blocks) != 2 || blocks.Blocks[1].TxId != "tx2" {
+		t.Errorf("ListBlocks返回%v", blocks)
+	}
+
+	if _, err = client.Query(ctx, &ChaincodeRequest{}); err == nil {
+		t.Error("未实现的方法应返回错误")
+	}
+}
+
+func TestSubscribeEventsStream(t *testing.T) {
+	client := newTestClient(t, &echoServer{})
+	stream, err := client.SubscribeEvents(context.Background(), &EventsRequest{ChannelId: "mychannel", ChaincodeId: "mycc", EventFilter: "transfer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*Event{
+		{Chaincode: &ChaincodeEvent{TxId: "tx1", ChaincodeId: "mycc", EventName: "transfer"}},
+		{Block: &BlockEvent{ChannelId: "mychannel", Number: 3, Transactions: []*FilteredTransaction{{TxId: "tx1", ValidationCode: "VALID"}}}},
+	}
+	for i, want := range expected {
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(event, want) {
+			t.Errorf("第%d个事件为%v，应为%v", i, event, want)
+		}
+	}
+	if _, err = stream.Recv(); err != io.EOF {
+		t.Errorf("事件推送结束后应返回io.EOF，实际为%v", err)
+	}
+}
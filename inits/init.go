@@ -1,19 +1,38 @@
 package inits
 
 import (
-	"github.com/kataras/golog"
 	"fabric-client/inits/parse"
+	"fabric-client/logger"
 	"fabric-client/util"
+	"fmt"
+	"os"
 )
 
+var log = logger.Named("inits")
+
 //init 初始化配置文件
 func init() {
-	//初始化数据库配置
-	err := util.ReadYamlConfig("config/db.yaml",&parse.DB)
-	if err!=nil {
-		golog.Errorf("初始化配置文件错误：%s", err)
+	//初始化应用配置，日志配置在其中，需要最先读取
+	err := util.ReadYamlConfig("config/app.yaml", &parse.App)
+	if err != nil {
+		log.Errorf("初始化配置文件错误：%s", err)
+	}
+	if err = logger.Init(parse.App.Log); err != nil {
+		log.Errorf("日志配置错误，使用默认配置：%s", err)
 	}
-	golog.Infof("db.yaml配置文件解析:%v",parse.DB)
+	log.Infow("配置文件解析", "path", "config/app.yaml", "config", fmt.Sprintf("%+v", parse.App))
 
+	//初始化数据库配置，可以通过DB_CONFIG环境变量指定配置文件
+	dbConfigPath := os.Getenv("DB_CONFIG")
+	if dbConfigPath == "" {
+		dbConfigPath = "config/db.yaml"
+	}
+	err = util.ReadYamlConfig(dbConfigPath, &parse.DB)
+	if err != nil {
+		log.Errorf("初始化配置文件错误：%s", err)
+	}
+	log.Infow("配置文件解析", "path", dbConfigPath, "config", fmt.Sprintf("%+v", parse.DB))
+	if err = parse.DB.Validate(); err != nil {
+		log.Fatalf("数据库配置错误：%s", err)
+	}
 }
-
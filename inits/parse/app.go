@@ -0,0 +1,117 @@
+package parse
+
+import "time"
+
+var App AppConfig
+
+type AppConfig struct {
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Async       AsyncConfig       `yaml:"async"`
+	Batch       BatchConfig       `yaml:"batch"`
+	Migrate     MigrateConfig     `yaml:"migrate"`
+	Outbox      OutboxConfig      `yaml:"outbox"`
+	Reconcile   ReconcileConfig   `yaml:"reconcile"`
+	Log         LogConfig         `yaml:"log"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Health      HealthConfig      `yaml:"health"`
+	Shutdown    ShutdownConfig    `yaml:"shutdown"`
+	SDK         SDKConfig         `yaml:"sdk"`
+	RateLimit   RateLimitConfig   `yaml:"rateLimit"`
+	GRPC        GRPCConfig        `yaml:"grpc"`
+}
+
+// 幂等请求配置
+type IdempotencyConfig struct {
+	Retention     time.Duration `yaml:"retention"`     // 幂等记录保留时长
+	CleanInterval time.Duration `yaml:"cleanInterval"` // 过期记录清理间隔
+}
+
+// 异步交易配置
+type AsyncConfig struct {
+	CommitTimeout   time.Duration `yaml:"commitTimeout"`   // 等待提交事件的超时时长
+	StatusRetention time.Duration `yaml:"statusRetention"` // 交易状态保留时长
+}
+
+// 批量执行配置
+type BatchConfig struct {
+	Parallelism  int   `yaml:"parallelism"`  // 并发执行数
+	MaxItems     int   `yaml:"maxItems"`     // 单次最大请求数
+	MaxBodyBytes int64 `yaml:"maxBodyBytes"` // 请求体最大字节数，默认8MB
+}
+
+// 数据库迁移配置
+type MigrateConfig struct {
+	Auto bool `yaml:"auto"` // 启动时自动执行迁移
+}
+
+// 交易索引outbox配置
+type OutboxConfig struct {
+	Dir           string        `yaml:"dir"`           // outbox文件目录
+	RetryInterval time.Duration `yaml:"retryInterval"` // 重试写入数据库的间隔
+}
+
+// 账本对账配置
+type ReconcileConfig struct {
+	MaxBlocks int `yaml:"maxBlocks"` // 单次对账的最大区块数
+}
+
+// 日志配置
+type LogConfig struct {
+	Format string            `yaml:"format"` // json或logfmt
+	Level  string            `yaml:"level"`  // debug、info、warn、error
+	Levels map[string]string `yaml:"levels"` // 按包设置日志级别，如db: warn
+}
+
+// Prometheus指标配置
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"` // 是否开启指标采集和指标接口
+	Path    string `yaml:"path"`    // 指标接口路径，默认/metrics
+}
+
+// 链路追踪配置
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"`    // otlp或stdout，为空时只传播trace context不导出
+	Endpoint    string  `yaml:"endpoint"`    // OTLP/HTTP collector地址，如localhost:4318
+	Insecure    bool    `yaml:"insecure"`    // 不使用TLS连接collector
+	SampleRatio float64 `yaml:"sampleRatio"` // 根span的采样比例，默认1
+	ServiceName string  `yaml:"serviceName"` // 服务名，默认fabric-client
+}
+
+// 就绪检查和节点诊断配置
+type HealthConfig struct {
+	CacheTTL time.Duration `yaml:"cacheTTL"` // 就绪检查结果的缓存时长
+	Timeout  time.Duration `yaml:"timeout"`  // 连接节点、查询和ping主库的超时时长
+}
+
+// 停止服务配置
+type ShutdownConfig struct {
+	Timeout time.Duration `yaml:"timeout"` // 等待处理中的请求和后台任务的超时时长
+}
+
+// 组织客户端配置
+type SDKConfig struct {
+	InitRetryInterval time.Duration `yaml:"initRetryInterval"` // 重试初始化失败组织的间隔
+}
+
+// 限流配置，apiKey和org按调用方和组织分别限制，routes按调用方对指定路由限制
+type RateLimitConfig struct {
+	Enabled bool                   `yaml:"enabled"`
+	APIKeys []string               `yaml:"apiKeys"` // 可以作为调用方标识的X-API-Key，其他值按客户端IP限流
+	APIKey  LimitConfig            `yaml:"apiKey"`
+	Org     LimitConfig            `yaml:"org"`
+	Routes  map[string]LimitConfig `yaml:"routes"` // key为路由路径，如/api/chaincode/exec
+}
+
+// 单个key的限制，为0的项不限制
+type LimitConfig struct {
+	Rate          float64 `yaml:"rate"`          // 每秒补充的令牌数
+	Burst         int     `yaml:"burst"`         // 令牌桶容量，默认为rate
+	MaxConcurrent int     `yaml:"maxConcurrent"` // 最大并发请求数
+}
+
+// gRPC接口配置
+type GRPCConfig struct {
+	Enabled bool   `yaml:"enabled"` // 是否开启gRPC接口
+	Addr    string `yaml:"addr"`    // 监听地址，默认:9090
+}
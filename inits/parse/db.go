@@ -1,23 +1,63 @@
 package parse
 
+import (
+	"fmt"
+	"time"
+)
+
 var DB DBConfig
 
 type DBConfig struct {
 	MasterDB DBYamlConfig `yaml:"master"`
 	Slave	DBYamlConfig	`yaml:"slave"`
+	Slaves []DBYamlConfig `yaml:"slaves"` // 多个从库，配置后忽略slave
+	HealthCheckInterval time.Duration `yaml:"healthCheckInterval"` // 从库健康检查间隔
+	Timezone string `yaml:"timezone"` // 应用时区，如Asia/Shanghai，为空时使用系统时区。数据库统一存储UTC时间
+}
+
+// Validate 校验时区配置，启动时调用，时区错误时拒绝启动，避免按错误的时区显示时间和解析日期
+func (config *DBConfig) Validate() error {
+	if _, err := time.LoadLocation(config.Timezone); err != nil {
+		return fmt.Errorf("时区%q配置错误: %v", config.Timezone, err)
+	}
+	return nil
+}
+
+// Location 返回配置的时区，配置已在启动时校验
+func (config *DBConfig) Location() *time.Location {
+	if config.Timezone == "" {
+		return time.Local
+	}
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return location
 }
 
 type DBYamlConfig struct {
-	Dialect	string `yaml:"dialect"`
+	Dialect	string `yaml:"dialect"` // mysql、postgres或sqlite3
 	User string `yaml:"user"`
 	Password string `yaml:"password"`
 	Host string `yaml:"host"`
 	Port int `yaml:"port"`
-	Database string `yaml:"database"`
+	Database string `yaml:"database"` // sqlite3为数据库文件路径
 	Charset string `yaml:"charset"`
+	SSLMode string `yaml:"sslMode"` // postgres的sslmode，默认disable
 	ShowSql bool `yaml:"showSql"`
 	LogLevel string `yaml:"logLevel"`
 	MaxIdleConns int `yaml:"maxIdleConns"`
 	MaxOpenConns int `yaml:"maxOpenConns"`
 }
 
+
+// String 打印配置时隐藏密码
+func (config DBYamlConfig) String() string {
+	password := ""
+	if config.Password != "" {
+		password = "******"
+	}
+	return fmt.Sprintf("{Dialect:%s User:%s Password:%s Host:%s Port:%d Database:%s Charset:%s SSLMode:%s ShowSql:%t LogLevel:%s MaxIdleConns:%d MaxOpenConns:%d}",
+		config.Dialect, config.User, password, config.Host, config.Port, config.Database, config.Charset, config.SSLMode,
+		config.ShowSql, config.LogLevel, config.MaxIdleConns, config.MaxOpenConns)
+}
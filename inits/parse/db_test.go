@@ -0,0 +1,18 @@
+package parse
+
+import "testing"
+
+func TestDBConfigValidate(t *testing.T) {
+	for _, timezone := range []string{"", "UTC", "Asia/Shanghai"} {
+		config := DBConfig{Timezone: timezone}
+		if err := config.Validate(); err != nil {
+			t.Errorf("时区%q应有效: %v", timezone, err)
+		}
+	}
+	for _, timezone := range []string{"Asia/Shanghia", "GMT+8"} {
+		config := DBConfig{Timezone: timezone}
+		if err := config.Validate(); err == nil {
+			t.Errorf("时区%q应校验失败", timezone)
+		}
+	}
+}
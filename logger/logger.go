@@ -0,0 +1,210 @@
+package logger
+
+import (
+	"fabric-client/inits/parse"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	zaplogfmt "github.com/sykesm/zap-logfmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 日志输出格式
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
+// 按包命名的日志，在第一次使用时按当前配置创建，Init后重新创建
+type Logger struct {
+	name string
+}
+
+var (
+	lock    sync.RWMutex
+	config  = parse.LogConfig{Format: FormatLogfmt, Level: "info"}
+	root    *zap.Logger
+	loggers = make(map[string]*sugarPair)
+)
+
+// direct用于调用方直接使用，wrapped用于Logger的方法，跳过一层调用栈以记录正确的调用位置
+type sugarPair struct {
+	direct  *zap.SugaredLogger
+	wrapped *zap.SugaredLogger
+}
+
+// Init 按配置重新创建所有日志，格式或级别配置错误时返回错误并保留原配置
+func Init(logConfig parse.LogConfig) error {
+	if logConfig.Format == "" {
+		logConfig.Format = FormatLogfmt
+	}
+	if logConfig.Level == "" {
+		logConfig.Level = "info"
+	}
+	newRoot, err := newRootLogger(logConfig)
+	if err != nil {
+		return err
+	}
+	for name, level := range logConfig.Levels {
+		if _, err = parseLevel(level); err != nil {
+			return fmt.Errorf("包%s的日志级别错误: %v", name, err)
+		}
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if root != nil {
+		root.Sync()
+	}
+	config = logConfig
+	root = newRoot
+	loggers = make(map[string]*sugarPair)
+	return nil
+}
+
+// Sync 刷新缓冲的日志，退出前调用
+func Sync() {
+	lock.RLock()
+	defer lock.RUnlock()
+	if root != nil {
+		root.Sync()
+	}
+}
+
+// Named 获取指定包的日志，级别由配置中的levels.<name>控制，未配置时使用level
+func Named(name string) *Logger {
+	return &Logger{name: name}
+}
+
+// Sugar 获取当前配置下的zap日志
+func (logger *Logger) Sugar() *zap.SugaredLogger {
+	return logger.pair().direct
+}
+
+func (logger *Logger) pair() *sugarPair {
+	lock.RLock()
+	pair, ok := loggers[logger.name]
+	lock.RUnlock()
+	if ok {
+		return pair
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if pair, ok = loggers[logger.name]; ok {
+		return pair
+	}
+	if root == nil {
+		root, _ = newRootLogger(config)
+	}
+
+	levelName := config.Level
+	if level, ok := config.Levels[logger.name]; ok {
+		levelName = level
+	}
+	level, _ := parseLevel(levelName)
+	named := root.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: core, level: level}
+	})).Named(logger.name)
+	pair = &sugarPair{
+		direct:  named.Sugar(),
+		wrapped: named.WithOptions(zap.AddCallerSkip(1)).Sugar(),
+	}
+	loggers[logger.name] = pair
+	return pair
+}
+
+// With 创建附加字段的日志，keysAndValues为交替的字段名和值
+func (logger *Logger) With(keysAndValues ...interface{}) *zap.SugaredLogger {
+	return logger.Sugar().With(keysAndValues...)
+}
+
+func (logger *Logger) Debugf(template string, args ...interface{}) {
+	logger.pair().wrapped.Debugf(template, args...)
+}
+
+func (logger *Logger) Infof(template string, args ...interface{}) {
+	logger.pair().wrapped.Infof(template, args...)
+}
+
+func (logger *Logger) Warnf(template string, args ...interface{}) {
+	logger.pair().wrapped.Warnf(template, args...)
+}
+
+func (logger *Logger) Errorf(template string, args ...interface{}) {
+	logger.pair().wrapped.Errorf(template, args...)
+}
+
+func (logger *Logger) Fatalf(template string, args ...interface{}) {
+	logger.pair().wrapped.Fatalf(template, args...)
+}
+
+func (logger *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	logger.pair().wrapped.Debugw(msg, keysAndValues...)
+}
+
+func (logger *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	logger.pair().wrapped.Infow(msg, keysAndValues...)
+}
+
+func (logger *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	logger.pair().wrapped.Warnw(msg, keysAndValues...)
+}
+
+func (logger *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	logger.pair().wrapped.Errorw(msg, keysAndValues...)
+}
+
+// 创建输出到标准输出的根日志，根日志不过滤级别，级别由各包的日志控制
+func newRootLogger(logConfig parse.LogConfig) (*zap.Logger, error) {
+	if _, err := parseLevel(logConfig.Level); err != nil {
+		return nil, err
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "ts"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	var encoder zapcore.Encoder
+	switch logConfig.Format {
+	case FormatJSON:
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case FormatLogfmt:
+		encoder = zaplogfmt.NewEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("不支持的日志格式: %s", logConfig.Format)
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), zapcore.DebugLevel)
+	return zap.New(&redactCore{Core: core}, zap.AddCaller(), zap.ErrorOutput(zapcore.Lock(os.Stderr))), nil
+}
+
+func parseLevel(level string) (zapcore.Level, error) {
+	var zapLevel zapcore.Level
+	err := zapLevel.UnmarshalText([]byte(strings.ToLower(level)))
+	return zapLevel, err
+}
+
+// 按包的级别过滤日志
+type levelCore struct {
+	zapcore.Core
+	level zapcore.Level
+}
+
+func (core *levelCore) Enabled(level zapcore.Level) bool {
+	return core.level.Enabled(level) && core.Core.Enabled(level)
+}
+
+func (core *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: core.Core.With(fields), level: core.level}
+}
+
+func (core *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !core.level.Enabled(entry.Level) {
+		return checked
+	}
+	return core.Core.Check(entry, checked)
+}
@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"go.uber.org/zap"
+)
+
+// 请求ID的请求头和响应头，请求中没有时自动生成
+const RequestIDHeader = "X-Request-ID"
+
+// 请求上下文中保存请求ID和日志字段的key
+const (
+	requestIDKey = "logger.requestID"
+	fieldsKey    = "logger.fields"
+)
+
+// 请求ID的最大长度，超过时重新生成
+const maxRequestIDLength = 128
+
+var (
+	web    = Named("web")
+	access = Named("access")
+)
+
+// New 请求日志中间件，为每个请求设置请求ID，请求结束后记录访问日志，不记录查询参数
+func New() iris.Handler {
+	return func(ctx iris.Context) {
+		requestID := ResolveRequestID(ctx.GetHeader(RequestIDHeader))
+		ctx.Values().Set(requestIDKey, requestID)
+		ctx.Header(RequestIDHeader, requestID)
+
+		start := time.Now()
+		ctx.Next()
+
+		sugar := access.Sugar()
+		if fields, ok := ctx.Values().Get(fieldsKey).([]interface{}); ok {
+			sugar = sugar.With(fields...)
+		}
+		sugar.Infow("request",
+			"request_id", requestID,
+			"method", ctx.Method(),
+			"path", ctx.Path(),
+			"status", ctx.GetStatusCode(),
+			"latency", time.Since(start),
+			"remote", ctx.RemoteAddr())
+	}
+}
+
+// RequestID 获取请求ID，未经过中间件时为空
+func RequestID(ctx iris.Context) string {
+	return ctx.Values().GetString(requestIDKey)
+}
+
+// AddFields 为请求之后的日志附加字段，如org、user、channel、chaincode、tx_id
+func AddFields(ctx iris.Context, keysAndValues ...interface{}) {
+	fields, _ := ctx.Values().Get(fieldsKey).([]interface{})
+	ctx.Values().Set(fieldsKey, append(fields, keysAndValues...))
+}
+
+// WithRequest 获取只附加了请求ID的日志，用于同一请求中的并发任务
+func WithRequest(ctx iris.Context) *zap.SugaredLogger {
+	return web.Sugar().With("request_id", RequestID(ctx))
+}
+
+// Access 访问日志，用于不经过中间件的请求，如gRPC调用
+func Access() *zap.SugaredLogger {
+	return access.Sugar()
+}
+
+// FromContext 获取附加了请求ID和请求字段的日志
+func FromContext(ctx iris.Context) *zap.SugaredLogger {
+	sugar := WithRequest(ctx)
+	if fields, ok := ctx.Values().Get(fieldsKey).([]interface{}); ok {
+		sugar = sugar.With(fields...)
+	}
+	return sugar
+}
+
+// ResolveRequestID 返回调用方传入的请求ID，为空或过长时重新生成，用于不经过中间件的请求，如gRPC调用
+func ResolveRequestID(requestID string) string {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return newRequestID()
+	}
+	return requestID
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(id)
+}
+
+// SafeURL 去掉URL中的用户信息和查询参数，用于记录回调地址等可能带有凭证的URL
+func SafeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "[INVALID URL]"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
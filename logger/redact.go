@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 替换敏感字段值的占位符
+const redacted = "[REDACTED]"
+
+// 需要脱敏的字段名，不区分大小写，字段名或其末尾的若干个单词与其中任意一个相同时脱敏
+var secretKeys = []string{"sign", "signature", "password", "secret", "token", "privatekey", "authorization", "apikey"}
+
+// IsSecretKey 判断字段名是否为敏感字段，如userSign、access_token、X-API-Key，signal、design等不脱敏
+func IsSecretKey(key string) bool {
+	words := splitKey(key)
+	for i := range words {
+		suffix := strings.Join(words[i:], "")
+		for _, secretKey := range secretKeys {
+			if suffix == secretKey {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 按分隔符和驼峰将字段名拆分为小写单词
+func splitKey(key string) []string {
+	words := make([]string, 0)
+	fields := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, field := range fields {
+		start := 0
+		runes := []rune(field)
+		for i := 1; i < len(runes); i++ {
+			if unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1]) {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+	return words
+}
+
+// 写入前替换敏感字段的值
+type redactCore struct {
+	zapcore.Core
+}
+
+func (core *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: core.Core.With(redactFields(fields))}
+}
+
+func (core *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(entry.Level) {
+		return checked.AddCore(entry, core)
+	}
+	return checked
+}
+
+func (core *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return core.Core.Write(entry, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	for i, field := range fields {
+		if IsSecretKey(field.Key) {
+			fields[i] = zap.String(field.Key, redacted)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestIsSecretKey(t *testing.T) {
+	cases := []struct {
+		key    string
+		secret bool
+	}{
+		{"sign", true},
+		{"Sign", true},
+		{"userSign", true},
+		{"signature", true},
+		{"password", true},
+		{"db_password", true},
+		{"clientSecret", true},
+		{"access_token", true},
+		{"privateKey", true},
+		{"private_key", true},
+		{"Authorization", true},
+		{"X-API-Key", true},
+		{"apiKey", true},
+		{"signal", false},
+		{"design", false},
+		{"signer", false},
+		{"tokens_used", false},
+		{"key", false},
+		{"tx_id", false},
+		{"", false},
+	}
+	for _, c := range cases {
+		if got := IsSecretKey(c.key); got != c.secret {
+			t.Errorf("IsSecretKey(%q) = %v，应为%v", c.key, got, c.secret)
+		}
+	}
+}
+
+func TestRedactCore(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(&redactCore{Core: observed}).With(zap.String("token", "t1"))
+	log.Info("request", zap.String("sign", "s1"), zap.String("signal", "SIGTERM"), zap.String("org", "Org1"))
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("日志条数为%d", len(entries))
+	}
+	expected := map[string]string{"token": redacted, "sign": redacted, "signal": "SIGTERM", "org": "Org1"}
+	fields := entries[0].ContextMap()
+	for key, value := range expected {
+		if fields[key] != value {
+			t.Errorf("字段%s为%v，应为%s", key, fields[key], value)
+		}
+	}
+}
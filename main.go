@@ -1,33 +1,97 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/middleware/i18n"
 	"github.com/kataras/iris/v12/mvc"
+	"fabric-client/db"
+	"fabric-client/db/migrate"
 	_ "fabric-client/inits"
+	"fabric-client/inits/parse"
+	"fabric-client/logger"
+	"fabric-client/metrics"
+	"fabric-client/openapi"
+	"fabric-client/ratelimit"
 	"fabric-client/sdkInit"
 	"fabric-client/service"
+	"fabric-client/tracing"
 	"fabric-client/web/controllers"
-	"log"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 )
 
+var log = logger.Named("main")
+
 var clientMap map[string]*sdkInit.Client
 
 func main() {
+	defer logger.Sync()
+
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := tracing.Init(parse.App.Tracing); err != nil {
+		log.Errorw("初始化链路追踪失败", "error", err)
+	}
+	defer tracing.Shutdown(context.Background())
+
 	var err error
 	clientMap, err = sdkInit.InitClientMap()
 	service.ClientMap=clientMap
 	defer sdkInit.CloseClientMap(clientMap)
+	defer db.Close()
 
 	if err != nil {
-		fmt.Println(err.Error())
+		log.Errorw("读取组织客户端配置失败", "error", err)
+		return
+	}
+	for _, client := range clientMap {
+		if status := client.Status(); !status.Ready {
+			log.Warnw("组织客户端不可用，其他组织正常提供服务", "org", status.OrgName, "error", status.Error)
+		}
+	}
+
+	if parse.App.Migrate.Auto {
+		if err = migrate.Run(db.MasterEngine()); err != nil {
+			log.Errorw("数据库迁移失败", "error", err)
+			return
+		}
+	}
+
+	db.StartHealthCheck()
+	if err = service.StartOutbox(); err != nil {
+		log.Errorw("启动outbox失败", "error", err)
 		return
 	}
+	service.StartIdempotencyCleaner()
+	service.StartTxTrackerCleaner()
+	service.StartClientInitRetry()
 
 	app := iris.New()
 
-	app.Logger().SetLevel("debug")
+	// iris框架自身的日志级别，应用日志由logger包输出
+	irisLogLevel := parse.App.Log.Level
+	if irisLogLevel == "" {
+		irisLogLevel = "info"
+	}
+	app.Logger().SetLevel(irisLogLevel)
+	app.Use(logger.New())
+	app.Use(tracing.New())
+	if parse.App.Metrics.Enabled {
+		app.Use(metrics.New())
+		app.Get(metricsPath(), metrics.Handler())
+	}
 
 	app.Use(i18n.New(i18n.Config{
 		Default:      "en",
@@ -36,20 +100,126 @@ func main() {
 			"en": "./locale/locale_en-US.ini",
 			"zh": "./locale/locale_zh-CN.ini"}}))
 
-	mvcApp := mvc.New(app.Party("/api"))
+	mvc.New(app.Party("/")).Handle(new(controllers.HealthController))
+
+	ratelimit.Init(parse.App.RateLimit)
+	mvcApp := mvc.New(app.Party("/api", controllers.RateLimit()))
 	mvcApp.Register(clientMap)
 	mvcApp.Handle(new(controllers.FabricSDKController))
 
+	// 接口文档根据已注册的路由生成，不经过限流
+	app.Get("/api/openapi.json", openapi.Handler(controllers.OpenAPI(app.GetRoutes())))
+	app.Get("/api/docs", openapi.SwaggerUI("/api/openapi.json", "/api/docs/assets"))
+	app.HandleDir("/api/docs/assets", "./static/swagger-ui")
+
+	var grpcServer *controllers.GRPCServer
+	if parse.App.GRPC.Enabled {
+		listener, err := net.Listen("tcp", grpcAddr())
+		if err != nil {
+			// 不能直接退出，需要停止已启动的后台任务并执行defer关闭SDK和数据库连接
+			log.Errorw("监听gRPC地址失败", "addr", grpcAddr(), "error", err)
+			stopBackground()
+			return
+		}
+		grpcServer = controllers.NewGRPCServer(clientMap)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Errorw("gRPC服务异常停止", "error", err)
+			}
+		}()
+		log.Infow("gRPC服务已启动", "addr", listener.Addr().String())
+	}
+
+	// shutdownOnSignal等待处理中的请求完成后关闭done
+	done := make(chan struct{})
+	go shutdownOnSignal(app, grpcServer, done)
+
 	// 启动服务
 	err = app.Run(
 		iris.Addr(":8080"),                            // 地址
 		iris.WithCharset("UTF-8"),                     // 国际化
 		iris.WithOptimizations,                        // 自动优化
 		iris.WithoutServerError(iris.ErrServerClosed), // 忽略框架错误
+		iris.WithoutInterruptHandler,                  // 由shutdownOnSignal处理退出信号
 	)
 
 	if err != nil {
-		log.Println(err.Error())
-		panic(err.Error())
+		// HTTP服务启动失败时不会收到退出信号，直接停止gRPC服务
+		log.Errorw("启动服务失败", "error", err)
+		if grpcServer != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+			if err := grpcServer.Shutdown(ctx); err != nil {
+				log.Warnw("等待处理中的gRPC调用超时", "error", err)
+			}
+			cancel()
+		}
+	} else {
+		// app.Run在开始停止时即返回，需等待处理中的请求完成
+		<-done
+	}
+	stopBackground()
+	log.Infow("服务已停止")
+}
+
+// 在超时时间内等待后台任务完成，之后由main中的defer关闭SDK和数据库连接
+func stopBackground() {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+	defer cancel()
+	if err := service.Shutdown(ctx); err != nil {
+		log.Warnw("部分后台任务未完成", "error", err, "outbox_pending", service.IndexOutbox.Pending())
+	}
+}
+
+// 收到SIGINT或SIGTERM后同时停止HTTP和gRPC服务接收新请求，在超时时间内等待处理中的请求完成后关闭done
+func shutdownOnSignal(app *iris.Application, grpcServer *controllers.GRPCServer, done chan<- struct{}) {
+	defer close(done)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	sig := <-signals
+	log.Infow("收到退出信号，开始停止服务", "signal", sig.String(), "timeout", shutdownTimeout().String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+	defer cancel()
+	var wg sync.WaitGroup
+	if grpcServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := grpcServer.Shutdown(ctx); err != nil {
+				log.Warnw("等待处理中的gRPC调用超时", "error", err)
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := app.Shutdown(ctx); err != nil {
+			log.Warnw("等待处理中的请求超时", "error", err)
+		}
+	}()
+	wg.Wait()
+}
+
+// 停止服务时等待请求和后台任务的超时时长，默认30秒
+func shutdownTimeout() time.Duration {
+	if parse.App.Shutdown.Timeout > 0 {
+		return parse.App.Shutdown.Timeout
+	}
+	return 30 * time.Second
+}
+
+// gRPC监听地址，默认:9090
+func grpcAddr() string {
+	if parse.App.GRPC.Addr != "" {
+		return parse.App.GRPC.Addr
+	}
+	return ":9090"
+}
+
+// 指标接口的路径，默认/metrics
+func metricsPath() string {
+	if parse.App.Metrics.Path != "" {
+		return parse.App.Metrics.Path
 	}
+	return "/metrics"
 }
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// 指标名称的前缀
+const namespace = "fabric_client"
+
+// 操作结果的标签值
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// 事件推送结果的标签值
+const (
+	EventReceived        = "received"         // 接收到链码事件
+	EventTimeout         = "timeout"          // 等待链码事件超时
+	EventCallbackSuccess = "callback_success" // 回调地址返回2xx
+	EventCallbackFailed  = "callback_failed"  // 回调请求失败或返回非2xx
+)
+
+// 链码调用的延迟分布较宽，从10ms到1分钟
+var fabricBuckets = []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+var (
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP请求的处理时长，按路由、方法和状态码区分",
+		Buckets:   fabricBuckets,
+	}, []string{"route", "method", "status"})
+
+	SDKOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "sdk",
+		Name:      "operation_duration_seconds",
+		Help:      "SDK操作的时长，operation为create_channel、join_channel、install、instantiate、upgrade、exec、exec_async、simulate、query",
+		Buckets:   fabricBuckets,
+	}, []string{"org", "operation", "status"})
+
+	EndorsementDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "sdk",
+		Name:      "endorsement_duration_seconds",
+		Help:      "收集背书的时长",
+		Buckets:   fabricBuckets,
+	}, []string{"channel", "chaincode"})
+
+	CommitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "sdk",
+		Name:      "commit_duration_seconds",
+		Help:      "发送交易到接收到提交事件的时长",
+		Buckets:   fabricBuckets,
+	}, []string{"channel", "chaincode", "status"})
+
+	EventDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "event",
+		Name:      "deliveries_total",
+		Help:      "链码事件的接收和回调结果",
+	}, []string{"outcome"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "数据库操作的时长",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "status"})
+
+	RateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "rate_limited_total",
+		Help:      "因限流被拒绝的请求数",
+	}, []string{"scope"})
+)
+
+func init() {
+	prometheus.MustRegister(HTTPRequestDuration, SDKOperationDuration, EndorsementDuration, CommitDuration, EventDeliveries, DBQueryDuration, RateLimited)
+}
+
+// Handler 输出Prometheus格式的指标
+func Handler() iris.Handler {
+	return iris.FromStd(promhttp.Handler())
+}
+
+// New HTTP请求指标中间件，route使用路由模板，避免路径参数导致标签过多
+func New() iris.Handler {
+	return func(ctx iris.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := "unmatched"
+		if currentRoute := ctx.GetCurrentRoute(); currentRoute != nil {
+			route = currentRoute.Path()
+		}
+		HTTPRequestDuration.WithLabelValues(route, ctx.Method(), strconv.Itoa(ctx.GetStatusCode())).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveSDK 记录一次SDK操作的时长和结果
+func ObserveSDK(org string, operation string, start time.Time, err error) {
+	SDKOperationDuration.WithLabelValues(org, operation, Status(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveDB 记录一次数据库操作的时长和结果
+func ObserveDB(operation string, start time.Time, err error) {
+	DBQueryDuration.WithLabelValues(operation, Status(err)).Observe(time.Since(start).Seconds())
+}
+
+// Status 根据错误返回结果标签
+func Status(err error) string {
+	if err != nil {
+		return StatusError
+	}
+	return StatusSuccess
+}
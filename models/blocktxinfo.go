@@ -1,53 +1,213 @@
 package models
 
 import (
+	"context"
 	"fabric-client/db"
 	"fabric-client/util"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-xorm/xorm"
 )
 
+// 区块交易信息，表结构变更需要在db/migrate中增加迁移
 type BlockTXInfo struct {
-	Id int `json:"id" xorm:"pk autoincr INT(10) notnull"`
-	Number uint64 `json:"number" xorm:"varchar(255) notnull"`
+	Id           int    `json:"id" xorm:"pk autoincr INT(10) notnull"`
+	Number       uint64 `json:"number" xorm:"BIGINT(20) notnull index"`
 	PreviousHash string `json:"previous_hash" xorm:"varchar(255) notnull"`
-	TxId string `json:"tx_id" xorm:"varchar(255) notnull"`
-	Timestamp int64 `json:"timestamp" xorm:"bigInt notnull"`
-	ChannelId string `json:"channel_id" xorm:"varchar(255) notnull"`
+	TxId         string `json:"tx_id" xorm:"varchar(255) notnull index"`
+	Timestamp    int64  `json:"timestamp" xorm:"bigInt notnull index"`
+	ChannelId    string `json:"channel_id" xorm:"varchar(255) notnull index"`
+	Creator      string `json:"creator" xorm:"varchar(255)"`
+	TimestampNs  int64  `json:"timestamp_ns" xorm:"BIGINT(20) notnull default 0"` // UTC纳秒时间戳
+	Time         string `json:"time,omitempty" xorm:"-"`                             // 按请求的时区和格式渲染的时间
+}
+
+func (BlockTXInfo) TableName() string {
+	return "block_t_x_info"
 }
 
 //加入信息
-func CreateBlockInfo(blocktxinfo ...*BlockTXInfo) (int64,error) {
+func CreateBlockInfo(ctx context.Context, blocktxinfo ...*BlockTXInfo) (count int64, err error) {
+	defer observeDB(ctx, "create_block_info", time.Now(), &err)
 	e:=db.MasterEngine()
 	return	e.Insert(blocktxinfo)
 }
 
+// 允许排序的列，支持json字段名和结构体字段名
+var blockSortColumns = map[string]string{
+	"id":            "id",
+	"Id":            "id",
+	"number":        "number",
+	"Number":        "number",
+	"previous_hash": "previous_hash",
+	"PreviousHash":  "previous_hash",
+	"tx_id":         "tx_id",
+	"TxId":          "tx_id",
+	"timestamp":     "timestamp",
+	"Timestamp":     "timestamp",
+	"channel_id":    "channel_id",
+	"ChannelId":     "channel_id",
+}
+
+//检查排序列和排序方式是否合法
+func ValidBlockSort(sortName string, sortOrder string) bool {
+	if sortName == "" {
+		return true
+	}
+	if _, ok := blockSortColumns[sortName]; !ok {
+		return false
+	}
+	return sortOrder == "" || sortOrder == "asc" || sortOrder == "desc"
+}
+
 //获取分页区块数据
-func GetPaginationBlock(page *util.Pagination) ([]*BlockTXInfo, int64, error) {
-	e := db.MasterEngine()
-	blocktxinfo := make([]*BlockTXInfo, 0)
+func GetPaginationBlock(ctx context.Context, page *util.Pagination) (blocktxinfo []*BlockTXInfo, count int64, err error) {
+	defer observeDB(ctx, "get_pagination_block", time.Now(), &err)
+	if !ValidBlockSort(page.SortName, page.SortOrder) {
+		return nil, 0, fmt.Errorf("不支持的排序列: %s %s", page.SortName, page.SortOrder)
+	}
+
+	e := db.ReadEngine()
+	blocktxinfo = make([]*BlockTXInfo, 0)
 	s := e.Limit(page.Limit, page.Start)
-	if page.SortName != "" {
+	applyBlockFilters(s, page)
+	if column, ok := blockSortColumns[page.SortName]; ok {
 		switch page.SortOrder {
 		case "asc":
-			s.Asc(page.SortName)
+			s.Asc(column)
 		case "desc":
-			s.Desc(page.SortName)
+			s.Desc(column)
 		}
 	}
-	count, err := s.FindAndCount(&blocktxinfo)
+	count, err = s.FindAndCount(&blocktxinfo)
+	formatBlockTime(blocktxinfo, page)
 	return blocktxinfo, count, err
 }
 
+//按区块号和id游标分页获取区块数据，返回下一页的游标，没有下一页时为空
+func GetCursorBlock(ctx context.Context, page *util.Pagination) (blocktxinfo []*BlockTXInfo, nextCursor string, err error) {
+	defer observeDB(ctx, "get_cursor_block", time.Now(), &err)
+	e := db.ReadEngine()
+	blocktxinfo = make([]*BlockTXInfo, 0)
+	s := e.Limit(page.Limit + 1)
+	applyBlockFilters(s, page)
+
+	desc := page.SortOrder == "desc"
+	if page.Cursor != "" {
+		var number uint64
+		var id int
+		if number, id, err = util.DecodeCursor(page.Cursor); err != nil {
+			return nil, "", err
+		}
+		if desc {
+			s.And("(number<? or (number=? and id<?))", number, number, id)
+		} else {
+			s.And("(number>? or (number=? and id>?))", number, number, id)
+		}
+	}
+
+	if desc {
+		s.Desc("number", "id")
+	} else {
+		s.Asc("number", "id")
+	}
+
+	if err = s.Find(&blocktxinfo); err != nil {
+		return nil, "", err
+	}
+
+	if len(blocktxinfo) > page.Limit {
+		blocktxinfo = blocktxinfo[:page.Limit]
+		last := blocktxinfo[len(blocktxinfo)-1]
+		nextCursor = util.EncodeCursor(last.Number, last.Id)
+	}
+	formatBlockTime(blocktxinfo, page)
+	return blocktxinfo, nextCursor, nil
+}
+
+//按分页参数中的时区和格式渲染区块时间
+func formatBlockTime(blocktxinfo []*BlockTXInfo, page *util.Pagination) {
+	for _, block := range blocktxinfo {
+		block.Time = page.FormatTime(block.TimestampNs)
+	}
+}
+
+//按分页参数中的过滤条件查询区块
+func applyBlockFilters(s *xorm.Session, page *util.Pagination) {
+	if page.ChannelID != "" {
+		s.And("channel_id=?", page.ChannelID)
+	}
+	if page.TxIDPrefix != "" {
+		s.And("tx_id like ? escape '!'", escapeLike(page.TxIDPrefix)+"%")
+	}
+	if page.MinNumber >= 0 {
+		s.And("number>=?", page.MinNumber)
+	}
+	if page.MaxNumber >= 0 {
+		s.And("number<=?", page.MaxNumber)
+	}
+	if page.StartTime > 0 {
+		s.And("timestamp>=?", page.StartTime)
+	}
+	if page.EndTime > 0 {
+		s.And("timestamp<=?", page.EndTime)
+	}
+}
+
+//转义like查询中的通配符，使用各数据库写法一致的!作为转义符
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
+
 //获取所有区块数据
-func GetAllBlock() ([]*BlockTXInfo, int64, error) {
-	e := db.MasterEngine()
-	blocktxinfo := make([]*BlockTXInfo, 0)
-	count, err := e.FindAndCount(&blocktxinfo)
+func GetAllBlock(ctx context.Context) (blocktxinfo []*BlockTXInfo, count int64, err error) {
+	defer observeDB(ctx, "get_all_block", time.Now(), &err)
+	e := db.ReadEngine()
+	blocktxinfo = make([]*BlockTXInfo, 0)
+	count, err = e.FindAndCount(&blocktxinfo)
 	return blocktxinfo, count, err
 }
 
 //根据TxId获取blocktxinfo
-func GetBlockByTxId(blocktxinfo *BlockTXInfo) (*BlockTXInfo, error) {
+func GetBlockByTxId(ctx context.Context, blocktxinfo *BlockTXInfo) (_ *BlockTXInfo, err error) {
+	defer observeDB(ctx, "get_block_by_tx_id", time.Now(), &err)
+	e := db.ReadEngine()
+	_, err = e.Where("tx_id=?", blocktxinfo.TxId).Get(blocktxinfo)
+	return blocktxinfo, err
+}
+//获取通道中指定区块号范围内的区块交易信息，对账使用，读取主库
+func GetBlocksByNumberRange(ctx context.Context, channelId string, start uint64, end uint64) (blocktxinfo []*BlockTXInfo, err error) {
+	defer observeDB(ctx, "get_blocks_by_number_range", time.Now(), &err)
 	e := db.MasterEngine()
-	_, err := e.Where("tx_id=?", blocktxinfo.TxId).Get(blocktxinfo)
+	blocktxinfo = make([]*BlockTXInfo, 0)
+	err = e.Where("channel_id=? and number>=? and number<=?", channelId, start, end).Asc("number", "id").Find(&blocktxinfo)
 	return blocktxinfo, err
-}
\ No newline at end of file
+}
+
+//根据交易ID批量获取区块交易信息，对账使用，读取主库
+func GetBlocksByTxIds(ctx context.Context, channelId string, txIds []string) (blocktxinfo []*BlockTXInfo, err error) {
+	blocktxinfo = make([]*BlockTXInfo, 0)
+	if len(txIds) == 0 {
+		return blocktxinfo, nil
+	}
+	defer observeDB(ctx, "get_blocks_by_tx_ids", time.Now(), &err)
+	e := db.MasterEngine()
+	err = e.Where("channel_id=?", channelId).In("tx_id", txIds).Asc("id").Find(&blocktxinfo)
+	return blocktxinfo, err
+}
+
+//根据id更新区块交易信息的所有列
+func UpdateBlockInfo(ctx context.Context, blocktxinfo *BlockTXInfo) (count int64, err error) {
+	defer observeDB(ctx, "update_block_info", time.Now(), &err)
+	e := db.MasterEngine()
+	return e.ID(blocktxinfo.Id).AllCols().Update(blocktxinfo)
+}
+
+//根据id删除区块交易信息
+func DeleteBlockInfo(ctx context.Context, id int) (count int64, err error) {
+	defer observeDB(ctx, "delete_block_info", time.Now(), &err)
+	e := db.MasterEngine()
+	return e.ID(id).Delete(new(BlockTXInfo))
+}
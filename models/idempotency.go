@@ -0,0 +1,56 @@
+package models
+
+import (
+	"context"
+	"fabric-client/db"
+	"time"
+)
+
+// 幂等请求记录，保存已提交交易的执行结果，异步提交的交易只保存交易ID
+type IdempotencyRecord struct {
+	Id               int64  `json:"id" xorm:"pk autoincr BIGINT(20) notnull"`
+	OrgName          string `json:"org_name" xorm:"varchar(255) notnull unique(org_request)"`
+	RequestId        string `json:"request_id" xorm:"varchar(128) notnull unique(org_request)"`
+	Fingerprint      string `json:"fingerprint" xorm:"varchar(64) notnull default ''"` // 请求内容的sha256，相同请求ID的请求内容不同时拒绝重放
+	TxId             string `json:"tx_id" xorm:"varchar(255) notnull"`
+	TxValidationCode int32  `json:"tx_validation_code" xorm:"INT(10) notnull"`
+	Status           string `json:"status" xorm:"varchar(32) notnull default 'committed'"` // 异步提交时为pending，结果通过交易跟踪或交易历史确定
+	Payload          []byte `json:"payload" xorm:"blob"`
+	CreatedAt        int64  `json:"created_at" xorm:"bigInt notnull index"`
+}
+
+//加入幂等记录，先删除相同请求ID已过期但未被清理的记录，避免违反唯一约束
+func CreateIdempotencyRecord(ctx context.Context, record *IdempotencyRecord, since int64) (err error) {
+	defer observeDB(ctx, "create_idempotency_record", time.Now(), &err)
+	session := db.MasterEngine().NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+	if _, err := session.Where("org_name=? and request_id=? and created_at<?", record.OrgName, record.RequestId, since).Delete(new(IdempotencyRecord)); err != nil {
+		session.Rollback()
+		return err
+	}
+	if _, err := session.Insert(record); err != nil {
+		session.Rollback()
+		return err
+	}
+	return session.Commit()
+}
+
+//根据组织名和请求ID获取未过期的幂等记录，读取主库避免从库延迟
+func GetIdempotencyRecord(ctx context.Context, orgName string, requestId string, since int64) (record *IdempotencyRecord, has bool, err error) {
+	defer observeDB(ctx, "get_idempotency_record", time.Now(), &err)
+	e := db.MasterEngine()
+	record = new(IdempotencyRecord)
+	has, err = e.Where("org_name=? and request_id=? and created_at>=?", orgName, requestId, since).Get(record)
+	return record, has, err
+}
+
+//删除过期的幂等记录
+func DeleteIdempotencyRecordBefore(ctx context.Context, before int64) (count int64, err error) {
+	defer observeDB(ctx, "delete_idempotency_record", time.Now(), &err)
+	e := db.MasterEngine()
+	return e.Where("created_at<?", before).Delete(new(IdempotencyRecord))
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"context"
+	"fabric-client/db"
+	"fabric-client/db/migrate"
+	"fabric-client/inits/parse"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 使用临时sqlite数据库作为主库
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "models")
+	if err != nil {
+		panic(err)
+	}
+	parse.DB.MasterDB = parse.DBYamlConfig{Dialect: "sqlite3", Database: filepath.Join(dir, "models.db")}
+	if err := migrate.Run(db.MasterEngine()); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	db.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestCreateIdempotencyRecord(t *testing.T) {
+	ctx := context.Background()
+	expired := &IdempotencyRecord{OrgName: "Org1", RequestId: "r1", Fingerprint: "old", TxId: "tx1", Status: "committed", CreatedAt: 100}
+	if err := CreateIdempotencyRecord(ctx, expired, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未过期的记录不能覆盖
+	duplicate := &IdempotencyRecord{OrgName: "Org1", RequestId: "r1", Fingerprint: "new", TxId: "tx2", Status: "committed", CreatedAt: 300}
+	if err := CreateIdempotencyRecord(ctx, duplicate, 100); err == nil {
+		t.Error("相同请求ID的未过期记录应违反唯一约束")
+	}
+
+	// 已过期但未被清理的记录被替换
+	if err := CreateIdempotencyRecord(ctx, duplicate, 200); err != nil {
+		t.Fatal(err)
+	}
+	record, has, err := GetIdempotencyRecord(ctx, "Org1", "r1", 200)
+	if err != nil || !has {
+		t.Fatalf("GetIdempotencyRecord = %v, %v", has, err)
+	}
+	if record.TxId != "tx2" || record.Fingerprint != "new" {
+		t.Errorf("幂等记录为%+v，应为替换后的记录", record)
+	}
+
+	// 其他组织的相同请求ID不受影响
+	other := &IdempotencyRecord{OrgName: "Org2", RequestId: "r1", TxId: "tx3", Status: "committed", CreatedAt: 300}
+	if err := CreateIdempotencyRecord(ctx, other, 200); err != nil {
+		t.Fatal(err)
+	}
+	if _, has, _ := GetIdempotencyRecord(ctx, "Org1", "r1", 200); !has {
+		t.Error("其他组织的记录不应删除Org1的记录")
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"context"
+	"fabric-client/metrics"
+	"fabric-client/tracing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 记录数据库操作的时长和span，在函数开始处defer调用，err为函数的命名返回值
+func observeDB(ctx context.Context, operation string, start time.Time, err *error) {
+	metrics.ObserveDB(operation, start, *err)
+	tracing.Record(ctx, "db."+operation, start, *err,
+		attribute.String("db.operation", operation))
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"context"
+	"fabric-client/db"
+	"fabric-client/util"
+	"time"
+)
+
+// 交易历史，保存每次链码执行的完整调用信息
+type TxHistory struct {
+	Id             int64  `json:"id" xorm:"pk autoincr BIGINT(20) notnull"`
+	TxId           string `json:"tx_id" xorm:"varchar(255) notnull unique"`
+	ChannelId      string `json:"channel_id" xorm:"varchar(255) notnull"`
+	ChaincodeId    string `json:"chaincode_id" xorm:"varchar(255) notnull index(chaincode)"`
+	Fcn            string `json:"fcn" xorm:"varchar(255) notnull index(chaincode)"`
+	Args           string `json:"args" xorm:"text"`
+	OrgName        string `json:"org_name" xorm:"varchar(255) notnull index(caller)"`
+	UserName       string `json:"user_name" xorm:"varchar(255) notnull index(caller)"`
+	Creator        string `json:"creator" xorm:"varchar(255)"`
+	Status         string `json:"status" xorm:"varchar(32) notnull index"`
+	ValidationCode string `json:"validation_code" xorm:"varchar(64)"`
+	BlockNumber    uint64 `json:"block_number" xorm:"BIGINT(20)"`
+	BlockHash      string `json:"block_hash" xorm:"varchar(255)"`
+	PreviousHash   string `json:"previous_hash" xorm:"varchar(255)"`
+	Payload        []byte `json:"payload" xorm:"blob"`
+	Timestamp      int64  `json:"timestamp" xorm:"bigInt notnull index"`
+	TimestampNs    int64  `json:"timestamp_ns" xorm:"BIGINT(20) notnull default 0"` // UTC纳秒时间戳
+	Time           string `json:"time,omitempty" xorm:"-"`                             // 按请求的时区和格式渲染的时间
+}
+
+// 交易历史查询条件，空值不参与过滤
+type TxHistoryFilter struct {
+	ChaincodeId string
+	Fcn         string
+	OrgName     string
+	UserName    string
+	Status      string
+	StartTime   int64
+	EndTime     int64
+}
+
+//加入交易历史
+func CreateTxHistory(ctx context.Context, txHistory *TxHistory) (count int64, err error) {
+	defer observeDB(ctx, "create_tx_history", time.Now(), &err)
+	e := db.MasterEngine()
+	return e.Insert(txHistory)
+}
+
+//根据交易ID判断交易历史是否存在，读取主库
+func TxHistoryExists(ctx context.Context, txId string) (exists bool, err error) {
+	defer observeDB(ctx, "tx_history_exists", time.Now(), &err)
+	e := db.MasterEngine()
+	return e.Where("tx_id=?", txId).Exist(new(TxHistory))
+}
+
+//根据交易ID获取交易历史，读取主库
+func GetTxHistoryByTxId(ctx context.Context, txId string) (txHistory *TxHistory, has bool, err error) {
+	defer observeDB(ctx, "get_tx_history", time.Now(), &err)
+	e := db.MasterEngine()
+	txHistory = new(TxHistory)
+	has, err = e.Where("tx_id=?", txId).Get(txHistory)
+	return txHistory, has, err
+}
+
+//在同一事务中加入区块交易信息和交易历史，blockTXInfo为nil时只加入交易历史
+func CreateTxRecords(ctx context.Context, blockTXInfo *BlockTXInfo, txHistory *TxHistory) (err error) {
+	defer observeDB(ctx, "create_tx_records", time.Now(), &err)
+	session := db.MasterEngine().NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+	if blockTXInfo != nil {
+		if _, err := session.Insert(blockTXInfo); err != nil {
+			session.Rollback()
+			return err
+		}
+	}
+	if _, err := session.Insert(txHistory); err != nil {
+		session.Rollback()
+		return err
+	}
+	return session.Commit()
+}
+
+//分页查询交易历史
+func SearchTxHistory(ctx context.Context, filter *TxHistoryFilter, page *util.Pagination) (txHistories []*TxHistory, count int64, err error) {
+	defer observeDB(ctx, "search_tx_history", time.Now(), &err)
+	e := db.ReadEngine()
+	txHistories = make([]*TxHistory, 0)
+	s := e.Limit(page.Limit, page.Start)
+	if filter.ChaincodeId != "" {
+		s.And("chaincode_id=?", filter.ChaincodeId)
+	}
+	if filter.Fcn != "" {
+		s.And("fcn=?", filter.Fcn)
+	}
+	if filter.OrgName != "" {
+		s.And("org_name=?", filter.OrgName)
+	}
+	if filter.UserName != "" {
+		s.And("user_name=?", filter.UserName)
+	}
+	if filter.Status != "" {
+		s.And("status=?", filter.Status)
+	}
+	if filter.StartTime > 0 {
+		s.And("timestamp>=?", filter.StartTime)
+	}
+	if filter.EndTime > 0 {
+		s.And("timestamp<=?", filter.EndTime)
+	}
+	count, err = s.Desc("id").FindAndCount(&txHistories)
+	for _, txHistory := range txHistories {
+		txHistory.Time = page.FormatTime(txHistory.TimestampNs)
+	}
+	return txHistories, count, err
+}
@@ -0,0 +1,269 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris/v12/core/router"
+)
+
+// 参数位置
+const (
+	InQuery  = "query"
+	InPath   = "path"
+	InHeader = "header"
+)
+
+// 接口的文档描述，由控制器按方法名提供
+type Endpoint struct {
+	Summary     string
+	Description string
+	Tag         string
+	Params      []Param     // 路径参数按在路径中出现的顺序给出
+	Request     interface{} // 请求体类型的零值，nil为没有请求体
+	Response    interface{} // 成功时Data的类型的零值，nil为没有Data
+	SignSource  string      // 签名原串的拼接规则，不需要签名时为空
+}
+
+// 查询、路径或请求头参数，Type为JSON Schema类型，默认string
+type Param struct {
+	Name        string
+	In          string
+	Type        string
+	Required    bool
+	Description string
+}
+
+// 路由路径中的参数，如{param1:string}
+var routeParam = regexp.MustCompile(`\{([^:}]+)(:[^}]*)?\}`)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+type builder struct {
+	doc   *Document
+	types map[reflect.Type]string // 已加入components的类型
+}
+
+// Build 根据已注册的路由生成文档，只包含endpoints中有描述的路由，endpoints的key为控制器方法名
+func Build(info Info, routes []*router.Route, endpoints map[string]Endpoint) *Document {
+	b := &builder{
+		doc: &Document{
+			OpenAPI:    "3.0.3",
+			Info:       info,
+			Paths:      make(map[string]PathItem),
+			Components: Components{Schemas: make(map[string]*Schema)},
+		},
+		types: make(map[reflect.Type]string),
+	}
+	b.doc.Components.Schemas["Result"] = b.resultSchema(nil)
+
+	tags := make(map[string]bool)
+	for _, route := range routes {
+		name := route.MainHandlerName[strings.LastIndex(route.MainHandlerName, ".")+1:]
+		endpoint, ok := endpoints[name]
+		if !ok {
+			continue
+		}
+
+		routePath := openAPIPath(route.Tmpl().Src, endpoint.Params)
+		item, ok := b.doc.Paths[routePath]
+		if !ok {
+			item = make(PathItem)
+			b.doc.Paths[routePath] = item
+		}
+		item[strings.ToLower(route.Method)] = b.operation(name, endpoint)
+
+		if endpoint.Tag != "" && !tags[endpoint.Tag] {
+			tags[endpoint.Tag] = true
+			b.doc.Tags = append(b.doc.Tags, Tag{Name: endpoint.Tag})
+		}
+	}
+	return b.doc
+}
+
+// 将路由的参数写法转换为OpenAPI的写法，参数名按顺序使用endpoint中的路径参数名
+func openAPIPath(src string, params []Param) string {
+	names := make([]string, 0)
+	for _, param := range params {
+		if param.In == InPath {
+			names = append(names, param.Name)
+		}
+	}
+
+	i := 0
+	return routeParam.ReplaceAllStringFunc(src, func(param string) string {
+		name := routeParam.FindStringSubmatch(param)[1]
+		if i < len(names) {
+			name = names[i]
+		}
+		i++
+		return "{" + name + "}"
+	})
+}
+
+func (b *builder) operation(name string, endpoint Endpoint) *Operation {
+	operation := &Operation{
+		OperationID: name,
+		Summary:     endpoint.Summary,
+		Description: endpoint.Description,
+		SignSource:  endpoint.SignSource,
+	}
+	if endpoint.Tag != "" {
+		operation.Tags = []string{endpoint.Tag}
+	}
+
+	for _, param := range endpoint.Params {
+		paramType := param.Type
+		if paramType == "" {
+			paramType = "string"
+		}
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:        param.Name,
+			In:          param.In,
+			Description: param.Description,
+			Required:    param.Required || param.In == InPath,
+			Schema:      &Schema{Type: paramType},
+		})
+	}
+
+	if endpoint.Request != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: b.schemaOf(reflect.TypeOf(endpoint.Request))}},
+		}
+	}
+
+	operation.Responses = map[string]*Response{
+		"200": {
+			Description: "Code为0时成功",
+			Content:     map[string]MediaType{"application/json": {Schema: b.resultSchema(endpoint.Response)}},
+		},
+		"default": {
+			Description: "失败，Code、Error和HTTP状态码的对应关系见/api/errors",
+			Content:     map[string]MediaType{"application/json": {Schema: &Schema{Ref: "#/components/schemas/Result"}}},
+		},
+	}
+	return operation
+}
+
+// 统一的返回结构，Data为data的类型
+func (b *builder) resultSchema(data interface{}) *Schema {
+	dataSchema := &Schema{}
+	if data != nil {
+		dataSchema = b.schemaOf(reflect.TypeOf(data))
+	}
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"Code":    {Type: "integer", Description: "错误码，0为成功"},
+			"Message": {Type: "string", Description: "提示信息，按lang参数本地化"},
+			"Error":   {Type: "string", Description: "错误码对应的字符串标识，成功时为空"},
+			"Warning": {Type: "string", Description: "请求成功但有需要注意的情况"},
+			"Data":    dataSchema,
+		},
+	}
+}
+
+// 按encoding/json的规则生成类型的schema，具名结构体加入components
+func (b *builder) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + b.component(t)}
+	}
+	// interface等无法确定的类型
+	return &Schema{}
+}
+
+// 将具名结构体加入components，同名的不同类型加上包名区分
+func (b *builder) component(t reflect.Type) string {
+	if name, ok := b.types[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, exists := b.doc.Components.Schemas[name]; exists {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	// 先占位，避免递归引用的类型重复生成
+	b.types[t] = name
+	b.doc.Components.Schemas[name] = &Schema{}
+	b.doc.Components.Schemas[name] = b.structSchema(t)
+	return name
+}
+
+func (b *builder) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	b.addProperties(schema, t)
+	return schema
+}
+
+// 导出字段按json标签命名，未命名的嵌入结构体展开到外层
+func (b *builder) addProperties(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				b.addProperties(schema, fieldType)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = b.schemaOf(field.Type)
+	}
+}
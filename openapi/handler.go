@@ -0,0 +1,34 @@
+package openapi
+
+import "github.com/kataras/iris/v12"
+
+// Handler 返回文档的json
+func Handler(doc *Document) iris.Handler {
+	return func(ctx iris.Context) {
+		ctx.JSON(doc)
+	}
+}
+
+// SwaggerUI 展示specURL文档的Swagger UI页面，页面资源从本服务的assetsURL加载，不依赖CDN
+func SwaggerUI(specURL string, assetsURL string) iris.Handler {
+	return func(ctx iris.Context) {
+		ctx.HTML(swaggerUIPage, assetsURL, assetsURL, specURL)
+	}
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="UTF-8">
+  <title>fabric-client API</title>
+  <link rel="stylesheet" href="%s/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="%s/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: %q, dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
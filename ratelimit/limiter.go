@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"fabric-client/inits/parse"
+	"math"
+	"sync"
+	"time"
+)
+
+// 清理空闲key的间隔
+const pruneInterval = time.Minute
+
+// 当前时间，测试时替换
+var clock = time.Now
+
+// 是否配置了任一限制
+func enabled(limit parse.LimitConfig) bool {
+	return limit.Rate > 0 || limit.MaxConcurrent > 0
+}
+
+type bucket struct {
+	tokens   float64
+	refilled time.Time // 上次补充令牌的时间
+	inflight int
+}
+
+// 按key分别计算的令牌桶和并发配额
+type Limiter struct {
+	limit     parse.LimitConfig
+	lock      sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+func NewLimiter(limit parse.LimitConfig) *Limiter {
+	if limit.Rate > 0 && limit.Burst <= 0 {
+		limit.Burst = int(math.Ceil(limit.Rate))
+	}
+	return &Limiter{limit: limit, buckets: make(map[string]*bucket), lastPrune: clock()}
+}
+
+// Acquire 为key取一个令牌和一个并发配额，成功时返回释放并发配额的函数，
+// 失败时返回建议的重试等待时长
+func (limiter *Limiter) Acquire(key string) (release func(), retryAfter time.Duration, ok bool) {
+	if limiter == nil || !enabled(limiter.limit) {
+		return func() {}, 0, true
+	}
+
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	now := clock()
+	limiter.prune(now)
+	b, exists := limiter.buckets[key]
+	if !exists {
+		b = &bucket{tokens: float64(limiter.limit.Burst), refilled: now}
+		limiter.buckets[key] = b
+	}
+
+	if limiter.limit.MaxConcurrent > 0 && b.inflight >= limiter.limit.MaxConcurrent {
+		return nil, time.Second, false
+	}
+
+	if limiter.limit.Rate > 0 {
+		b.tokens = limiter.refill(b, now)
+		b.refilled = now
+		if b.tokens < 1 {
+			wait := time.Duration((1 - b.tokens) / limiter.limit.Rate * float64(time.Second))
+			return nil, wait, false
+		}
+		b.tokens--
+	}
+
+	b.inflight++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			limiter.lock.Lock()
+			b.inflight--
+			limiter.lock.Unlock()
+		})
+	}, 0, true
+}
+
+// 定期清理与新建状态相同的key，即没有进行中的请求且令牌已补满，清理后再次使用时不影响限流结果
+func (limiter *Limiter) prune(now time.Time) {
+	if now.Sub(limiter.lastPrune) < pruneInterval {
+		return
+	}
+	limiter.lastPrune = now
+	for key, b := range limiter.buckets {
+		if b.inflight == 0 && limiter.refill(b, now) >= float64(limiter.limit.Burst) {
+			delete(limiter.buckets, key)
+		}
+	}
+}
+
+// 按经过的时间计算桶中的令牌数
+func (limiter *Limiter) refill(b *bucket, now time.Time) float64 {
+	if limiter.limit.Rate <= 0 {
+		return float64(limiter.limit.Burst)
+	}
+	return math.Min(float64(limiter.limit.Burst), b.tokens+now.Sub(b.refilled).Seconds()*limiter.limit.Rate)
+}
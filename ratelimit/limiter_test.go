@@ -0,0 +1,158 @@
+package ratelimit
+
+import (
+	"fabric-client/inits/parse"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// 使用可控制的时间，返回推进时间的函数
+func fakeClock(t *testing.T) func(time.Duration) {
+	current := time.Unix(1600000000, 0)
+	clock = func() time.Time { return current }
+	t.Cleanup(func() { clock = time.Now })
+	return func(d time.Duration) { current = current.Add(d) }
+}
+
+func TestLimiterTokenBucket(t *testing.T) {
+	advance := fakeClock(t)
+	limiter := NewLimiter(parse.LimitConfig{Rate: 2, Burst: 3})
+
+	steps := []struct {
+		advance    time.Duration
+		ok         bool
+		retryAfter time.Duration
+	}{
+		{0, true, 0},
+		{0, true, 0},
+		{0, true, 0},
+		{0, false, 500 * time.Millisecond}, // 令牌用完，每秒补充2个
+		{250 * time.Millisecond, false, 250 * time.Millisecond},
+		{250 * time.Millisecond, true, 0},
+		{time.Hour, true, 0}, // 最多补满burst个
+		{0, true, 0},
+		{0, true, 0},
+		{0, false, 500 * time.Millisecond},
+	}
+	for i, step := range steps {
+		advance(step.advance)
+		release, retryAfter, ok := limiter.Acquire("caller")
+		if ok != step.ok || retryAfter != step.retryAfter {
+			t.Fatalf("第%d步: ok=%v retryAfter=%s，应为ok=%v retryAfter=%s", i, ok, retryAfter, step.ok, step.retryAfter)
+		}
+		if ok {
+			release()
+		}
+	}
+
+	// 不同的key分别计算
+	if _, _, ok := limiter.Acquire("other"); !ok {
+		t.Error("其他key不应被限流")
+	}
+}
+
+func TestLimiterDefaultBurst(t *testing.T) {
+	fakeClock(t)
+	limiter := NewLimiter(parse.LimitConfig{Rate: 1.5})
+	for i := 0; i < 2; i++ {
+		if _, _, ok := limiter.Acquire("caller"); !ok {
+			t.Fatalf("第%d个请求被限流，burst默认为rate向上取整", i)
+		}
+	}
+	if _, _, ok := limiter.Acquire("caller"); ok {
+		t.Error("超过burst的请求应被限流")
+	}
+}
+
+func TestLimiterMaxConcurrent(t *testing.T) {
+	fakeClock(t)
+	limiter := NewLimiter(parse.LimitConfig{MaxConcurrent: 2})
+
+	release1, _, ok1 := limiter.Acquire("caller")
+	_, _, ok2 := limiter.Acquire("caller")
+	if !ok1 || !ok2 {
+		t.Fatal("未超过并发数的请求被限流")
+	}
+	if _, retryAfter, ok := limiter.Acquire("caller"); ok || retryAfter != time.Second {
+		t.Fatalf("超过并发数: ok=%v retryAfter=%s", ok, retryAfter)
+	}
+
+	// 重复释放只释放一次
+	release1()
+	release1()
+	if _, _, ok := limiter.Acquire("caller"); !ok {
+		t.Fatal("释放后应能取到并发配额")
+	}
+	if _, _, ok := limiter.Acquire("caller"); ok {
+		t.Error("重复释放不应增加并发配额")
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	var nilLimiter *Limiter
+	limiters := map[string]*Limiter{
+		"nil":      nilLimiter,
+		"no limit": NewLimiter(parse.LimitConfig{}),
+	}
+	for name, limiter := range limiters {
+		for i := 0; i < 100; i++ {
+			release, _, ok := limiter.Acquire("caller")
+			if !ok {
+				t.Fatalf("%s: 未配置限制时不应限流", name)
+			}
+			release()
+		}
+	}
+}
+
+func TestLimiterPrune(t *testing.T) {
+	advance := fakeClock(t)
+	// 每100秒补充1个令牌
+	limiter := NewLimiter(parse.LimitConfig{Rate: 0.01, Burst: 10, MaxConcurrent: 5})
+
+	for i := 0; i < 100; i++ {
+		release, _, _ := limiter.Acquire(fmt.Sprintf("caller%d", i))
+		release()
+	}
+	busy, _, _ := limiter.Acquire("busy")
+
+	// 令牌未补满时保留
+	advance(pruneInterval)
+	limiter.Acquire("trigger")
+	if len(limiter.buckets) != 102 {
+		t.Fatalf("令牌未补满时不应清理，key数量为%d", len(limiter.buckets))
+	}
+
+	// 令牌补满且没有进行中的请求时清理，进行中的保留
+	advance(pruneInterval)
+	limiter.Acquire("trigger")
+	if _, ok := limiter.buckets["busy"]; !ok || len(limiter.buckets) != 2 {
+		t.Fatalf("清理后的key数量为%d", len(limiter.buckets))
+	}
+
+	busy()
+	advance(pruneInterval)
+	limiter.Acquire("trigger")
+	if _, ok := limiter.buckets["busy"]; ok {
+		t.Error("请求结束后应清理")
+	}
+}
+
+func TestCaller(t *testing.T) {
+	Init(parse.RateLimitConfig{APIKeys: []string{"billing"}})
+	cases := []struct {
+		apiKey string
+		caller string
+	}{
+		{"billing", "key:billing"},
+		{"unknown", "ip:10.0.0.1"},
+		{"", "ip:10.0.0.1"},
+		{"10.0.0.1", "ip:10.0.0.1"},
+	}
+	for _, c := range cases {
+		if caller := Caller(c.apiKey, "10.0.0.1"); caller != c.caller {
+			t.Errorf("Caller(%q) = %s，应为%s", c.apiKey, caller, c.caller)
+		}
+	}
+}
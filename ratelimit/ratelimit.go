@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"fabric-client/inits/parse"
+	"fabric-client/metrics"
+	"time"
+)
+
+// 限流的维度
+const (
+	ScopeAPIKey = "api_key"
+	ScopeRoute  = "route"
+	ScopeOrg    = "org"
+)
+
+var (
+	apiKeys        map[string]bool
+	apiKeyLimiter  *Limiter
+	orgLimiter     *Limiter
+	routeLimiters  map[string]*Limiter
+	limiterEnabled bool
+)
+
+// Init 按配置创建限流器，未开启时所有请求都放行
+func Init(config parse.RateLimitConfig) {
+	limiterEnabled = config.Enabled
+	apiKeys = make(map[string]bool, len(config.APIKeys))
+	for _, key := range config.APIKeys {
+		apiKeys[key] = true
+	}
+	apiKeyLimiter = NewLimiter(config.APIKey)
+	orgLimiter = NewLimiter(config.Org)
+	routeLimiters = make(map[string]*Limiter, len(config.Routes))
+	for route, limit := range config.Routes {
+		routeLimiters[route] = NewLimiter(limit)
+	}
+}
+
+// Caller 返回限流使用的调用方标识，apiKey在配置中时按apiKey，否则按客户端地址，
+// 避免调用方每次请求使用不同的apiKey绕过限制
+func Caller(apiKey string, remoteAddr string) string {
+	if apiKeys[apiKey] {
+		return "key:" + apiKey
+	}
+	return "ip:" + remoteAddr
+}
+
+// AcquireRequest 按调用方和调用方对路由的限制取配额，失败时返回触发限制的维度
+func AcquireRequest(apiKey string, route string) (release func(), retryAfter time.Duration, scope string, ok bool) {
+	if !limiterEnabled {
+		return func() {}, 0, "", true
+	}
+
+	releaseAPIKey, retryAfter, ok := apiKeyLimiter.Acquire(apiKey)
+	if !ok {
+		metrics.RateLimited.WithLabelValues(ScopeAPIKey).Inc()
+		return nil, retryAfter, ScopeAPIKey, false
+	}
+
+	releaseRoute, retryAfter, ok := routeLimiters[route].Acquire(apiKey + " " + route)
+	if !ok {
+		releaseAPIKey()
+		metrics.RateLimited.WithLabelValues(ScopeRoute).Inc()
+		return nil, retryAfter, ScopeRoute, false
+	}
+
+	return func() {
+		releaseRoute()
+		releaseAPIKey()
+	}, 0, "", true
+}
+
+// AcquireOrg 按组织的限制取配额，所有调用方共享
+func AcquireOrg(orgName string) (release func(), retryAfter time.Duration, ok bool) {
+	if !limiterEnabled {
+		return func() {}, 0, true
+	}
+
+	release, retryAfter, ok = orgLimiter.Acquire(orgName)
+	if !ok {
+		metrics.RateLimited.WithLabelValues(ScopeOrg).Inc()
+	}
+	return release, retryAfter, ok
+}
@@ -0,0 +1,182 @@
+package sdkInit
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+)
+
+// 节点类型
+const (
+	NodePeer    = "peer"
+	NodeOrderer = "orderer"
+)
+
+// 组织的节点诊断信息
+type OrgDiagnostics struct {
+	OrgName string             `json:"orgName"`
+	Nodes   []*NodeDiagnostics `json:"nodes"`
+	Error   string             `json:"error,omitempty"`
+}
+
+// 单个peer或orderer的诊断信息，证书过期时间为unix秒
+type NodeDiagnostics struct {
+	Type          string            `json:"type"`
+	URL           string            `json:"url"`
+	Reachable     bool              `json:"reachable"`
+	LatencyMs     int64             `json:"latencyMs"`
+	TLS           bool              `json:"tls"`
+	TLSCertExpiry int64             `json:"tlsCertExpiry,omitempty"` // 节点TLS证书的过期时间
+	TLSCAExpiry   int64             `json:"tlsCaExpiry,omitempty"`   // 配置的TLS CA证书的过期时间
+	Channels      []string          `json:"channels,omitempty"`      // peer已加入的通道
+	BlockHeights  map[string]uint64 `json:"blockHeights,omitempty"`  // peer在各配置通道上的区块高度
+	Errors        []string          `json:"errors,omitempty"`
+}
+
+// QueryJoinedChannels 查询组织内每个peer已加入的通道，key为peer的URL，
+// 所有peer都查询失败时返回错误
+func (client *Client) QueryJoinedChannels(timeout time.Duration) (map[string][]string, error) {
+	if err := client.CheckReady(); err != nil {
+		return nil, err
+	}
+
+	endpointConfig, err := client.endpointConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	orgPeers, ok := endpointConfig.PeersConfig(client.Org.OrgName)
+	if !ok || len(orgPeers) == 0 {
+		return nil, fmt.Errorf("【%s】组织未配置节点", client.Org.OrgName)
+	}
+
+	joined := make(map[string][]string)
+	var lastErr error
+	for _, peer := range orgPeers {
+		channels, err := client.queryPeerChannels(peer.URL, timeout)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		joined[peer.URL] = channels
+	}
+	if len(joined) == 0 {
+		return nil, lastErr
+	}
+	return joined, nil
+}
+
+func (client *Client) queryPeerChannels(peerURL string, timeout time.Duration) ([]string, error) {
+	response, err := client.ResmgmtClient.QueryChannels(resmgmt.WithTargetEndpoints(peerURL), resmgmt.WithTimeout(fab.PeerResponse, timeout))
+	if err != nil {
+		return nil, fmt.Errorf("查询节点【%s】已加入的通道失败: %v", peerURL, err)
+	}
+
+	channels := make([]string, 0, len(response.Channels))
+	for _, channelInfo := range response.Channels {
+		channels = append(channels, channelInfo.ChannelId)
+	}
+	return channels, nil
+}
+
+// Diagnose 检查组织内的peer和orderer的连通性、TLS证书过期时间，以及peer在配置通道上的区块高度
+func (client *Client) Diagnose(timeout time.Duration) *OrgDiagnostics {
+	diagnostics := &OrgDiagnostics{OrgName: client.Org.OrgName, Nodes: make([]*NodeDiagnostics, 0)}
+	if err := client.CheckReady(); err != nil {
+		diagnostics.Error = err.Error()
+		return diagnostics
+	}
+
+	endpointConfig, err := client.endpointConfig()
+	if err != nil {
+		diagnostics.Error = err.Error()
+		return diagnostics
+	}
+
+	orgPeers, _ := endpointConfig.PeersConfig(client.Org.OrgName)
+	for _, peer := range orgPeers {
+		node := probeNode(NodePeer, peer.URL, peer.GRPCOptions, peer.TLSCACert, timeout)
+		if node.Reachable {
+			client.diagnosePeerChannels(node, timeout)
+		}
+		diagnostics.Nodes = append(diagnostics.Nodes, node)
+	}
+
+	for _, orderer := range endpointConfig.OrderersConfig() {
+		diagnostics.Nodes = append(diagnostics.Nodes, probeNode(NodeOrderer, orderer.URL, orderer.GRPCOptions, orderer.TLSCACert, timeout))
+	}
+	return diagnostics
+}
+
+// 查询peer已加入的通道和配置通道上的区块高度
+func (client *Client) diagnosePeerChannels(node *NodeDiagnostics, timeout time.Duration) {
+	channels, err := client.queryPeerChannels(node.URL, timeout)
+	if err != nil {
+		node.Errors = append(node.Errors, err.Error())
+	} else {
+		node.Channels = channels
+	}
+
+	for _, channelID := range client.Channels {
+		ledgerClient, err := client.GetLedgerClient(&ChannelClientRequest{ChannelID: channelID, OrgName: client.Org.OrgName, UserName: client.Org.OrgAdmin})
+		if err != nil {
+			node.Errors = append(node.Errors, err.Error())
+			continue
+		}
+
+		info, err := ledgerClient.QueryInfo(ledger.WithTargetEndpoints(node.URL), ledger.WithTimeout(fab.PeerResponse, timeout))
+		if err != nil {
+			node.Errors = append(node.Errors, fmt.Sprintf("查询通道%s的区块高度失败: %v", channelID, err))
+			continue
+		}
+		if node.BlockHeights == nil {
+			node.BlockHeights = make(map[string]uint64)
+		}
+		node.BlockHeights[channelID] = info.BCI.Height
+	}
+}
+
+// 连接节点地址，启用TLS时完成握手并读取节点证书，不校验证书以便证书过期时也能返回过期时间
+func probeNode(nodeType string, url string, grpcOptions map[string]interface{}, tlsCACert *x509.Certificate, timeout time.Duration) *NodeDiagnostics {
+	node := &NodeDiagnostics{Type: nodeType, URL: url, TLS: endpoint.IsTLSEnabled(url)}
+	if tlsCACert != nil {
+		node.TLSCAExpiry = tlsCACert.NotAfter.Unix()
+	}
+
+	address := endpoint.ToAddress(url)
+	dialer := &net.Dialer{Timeout: timeout}
+	start := time.Now()
+	if !node.TLS {
+		conn, err := dialer.Dial("tcp", address)
+		if err != nil {
+			node.Errors = append(node.Errors, err.Error())
+			return node
+		}
+		conn.Close()
+		node.Reachable = true
+		node.LatencyMs = time.Since(start).Milliseconds()
+		return node
+	}
+
+	serverName, _ := grpcOptions["ssl-target-name-override"].(string)
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+	if err != nil {
+		node.Errors = append(node.Errors, err.Error())
+		return node
+	}
+	defer conn.Close()
+
+	node.Reachable = true
+	node.LatencyMs = time.Since(start).Milliseconds()
+	if certs := conn.ConnectionState().PeerCertificates; len(certs) > 0 {
+		node.TLSCertExpiry = certs[0].NotAfter.Unix()
+	}
+	return node
+}
@@ -1,10 +1,13 @@
 package sdkInit
 
 import (
+	"sync"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
 	mspclient "github.com/hyperledger/fabric-sdk-go/pkg/client/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
 )
 
@@ -18,9 +21,28 @@ type Client struct {
 	ResmgmtClient     *resmgmt.Client
 	MSPClient         *mspclient.Client
 	ChannelClients    map[string]*channel.Client
-	LedgerClients    map[string]*ledger.Client
-	SDKConfigPath     string `yaml:"sdkConfigPath"`
-	ChannelConfigPath string `yaml:"channelConfigPath"` // 通道配置路径
+	LedgerClients     map[string]*ledger.Client
+	SDKConfigPath     string   `yaml:"sdkConfigPath"`
+	ChannelConfigPath string   `yaml:"channelConfigPath"` // 通道配置路径
+	Channels          []string `yaml:"channels"`          // 组织节点应加入的通道，用于就绪检查和诊断
+
+	lock sync.Mutex // 保护ChannelClients和LedgerClients
+
+	stateLock   sync.RWMutex       // 保护SDK、ResmgmtClient、MSPClient、节点配置和初始化状态
+	endpoints   fab.EndpointConfig // 初始化时从SDK配置解析的节点配置
+	ready       bool
+	initErr     error
+	attempts    int
+	lastAttempt int64
+}
+
+// 组织客户端的初始化状态
+type ClientStatus struct {
+	OrgName     string `json:"orgName"`
+	Ready       bool   `json:"ready"`
+	Error       string `json:"error,omitempty"` // 最近一次初始化失败的原因
+	Attempts    int    `json:"attempts"`        // 初始化次数
+	LastAttempt int64  `json:"lastAttempt"`     // 最近一次初始化的时间
 }
 
 type Org struct {
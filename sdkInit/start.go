@@ -1,24 +1,34 @@
 package sdkInit
 
 import (
+	"fabric-client/logger"
+	"fabric-client/metrics"
 	"fmt"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
 	"gopkg.in/yaml.v2"
 	"os"
+	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
 	mspclient "github.com/hyperledger/fabric-sdk-go/pkg/client/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	mspctx "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	fabImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/ccpackager/gopackager"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/common/cauthdsl"
 )
 
+var log = logger.Named("sdkInit")
+
 var goPath = os.Getenv("GOPATH")
 
+// InitClientMap 读取客户端配置并初始化各组织的客户端，只有读取配置失败时返回错误。
+// 初始化失败的组织也加入map，状态为不可用，由RetryInit重试
 func InitClientMap() (map[string]*Client, error) {
 	clientConfig, err := readClientConfig("config/client-config.yaml")
 	if err != nil {
@@ -27,48 +37,129 @@ func InitClientMap() (map[string]*Client, error) {
 
 	clientMap := make(map[string]*Client, len(clientConfig.Clients))
 	for _, client := range clientConfig.Clients {
-		err = initClient(client)
-		if err != nil {
-			return nil, err
+		client.ChannelClients = make(map[string]*channel.Client)
+		client.LedgerClients = make(map[string]*ledger.Client)
+		if err = client.init(); err != nil {
+			log.Errorw("初始化组织客户端失败，稍后重试", "org", client.Org.OrgName, "error", err)
 		}
-
 		clientMap[client.Org.OrgName] = client
 	}
 	return clientMap, nil
 }
 
-func initClient(client *Client) error {
+// RetryInit 重新初始化不可用的组织客户端，返回仍不可用的组织数
+func RetryInit(clientMap map[string]*Client) int {
+	unavailable := 0
+	for _, client := range clientMap {
+		if client.Ready() {
+			continue
+		}
+		if err := client.init(); err != nil {
+			unavailable++
+			log.Warnw("重试初始化组织客户端失败", "org", client.Org.OrgName, "attempts", client.Status().Attempts, "error", err)
+			continue
+		}
+		log.Infow("组织客户端已恢复", "org", client.Org.OrgName)
+	}
+	return unavailable
+}
+
+// Ready 组织的SDK和管理客户端是否已初始化
+func (client *Client) Ready() bool {
+	client.stateLock.RLock()
+	defer client.stateLock.RUnlock()
+	return client.ready
+}
+
+// Status 返回组织客户端的初始化状态
+func (client *Client) Status() ClientStatus {
+	client.stateLock.RLock()
+	defer client.stateLock.RUnlock()
+	status := ClientStatus{
+		OrgName:     client.Org.OrgName,
+		Ready:       client.ready,
+		Attempts:    client.attempts,
+		LastAttempt: client.lastAttempt,
+	}
+	if client.initErr != nil {
+		status.Error = client.initErr.Error()
+	}
+	return status
+}
+
+// CheckReady 组织客户端不可用时返回包含初始化失败原因的错误
+func (client *Client) CheckReady() error {
+	status := client.Status()
+	if status.Ready {
+		return nil
+	}
+	return fmt.Errorf("【%s】组织的客户端不可用: %s", client.Org.OrgName, status.Error)
+}
+
+// 初始化组织客户端并记录状态
+func (client *Client) init() error {
+	sdk, resmgmtClient, mspClient, err := initClient(client)
+	var endpoints fab.EndpointConfig
+	if err == nil {
+		if endpoints, err = newEndpointConfig(client.Org.OrgName, sdk); err != nil {
+			sdk.Close()
+		}
+	}
+
+	client.stateLock.Lock()
+	defer client.stateLock.Unlock()
+	client.attempts++
+	client.lastAttempt = time.Now().Unix()
+	client.initErr = err
+	if err != nil {
+		return err
+	}
+	client.SDK = sdk
+	client.ResmgmtClient = resmgmtClient
+	client.MSPClient = mspClient
+	client.endpoints = endpoints
+	client.ready = true
+	return nil
+}
+
+func initClient(client *Client) (*fabsdk.FabricSDK, *resmgmt.Client, *mspclient.Client, error) {
 	sdk, err := fabsdk.New(config.FromFile(client.SDKConfigPath))
 	if err != nil {
-		return fmt.Errorf("初始化【%s】组织的FabricSDK失败:%v", client.Org.OrgName, err)
+		return nil, nil, nil, fmt.Errorf("初始化【%s】组织的FabricSDK失败:%v", client.Org.OrgName, err)
 	}
 
 	clientProvider := sdk.Context(fabsdk.WithOrg(client.Org.OrgName), fabsdk.WithUser(client.Org.OrgAdmin))
 	if clientProvider == nil {
-		return fmt.Errorf("创建【%s】组织的资源管理客户端Context失败", client.Org.OrgName)
+		sdk.Close()
+		return nil, nil, nil, fmt.Errorf("创建【%s】组织的资源管理客户端Context失败", client.Org.OrgName)
 	}
 
 	resmgmtClient, err := resmgmt.New(clientProvider)
 	if err != nil {
-		return fmt.Errorf("创建【%s】组织的通道管理客户端失败: %v", client.Org.OrgName, err)
+		sdk.Close()
+		return nil, nil, nil, fmt.Errorf("创建【%s】组织的通道管理客户端失败: %v", client.Org.OrgName, err)
 	}
 
 	mspClient, err := mspclient.New(sdk.Context(), mspclient.WithOrg(client.Org.OrgName))
 	if err != nil {
-		return fmt.Errorf("创建【%s】组织的OrgMSP客户端实例失败: %v", client.Org.OrgName, err)
+		sdk.Close()
+		return nil, nil, nil, fmt.Errorf("创建【%s】组织的OrgMSP客户端实例失败: %v", client.Org.OrgName, err)
 	}
-
-	client.SDK = sdk
-	client.ResmgmtClient = resmgmtClient
-	client.MSPClient = mspClient
-	client.ChannelClients = make(map[string]*channel.Client)
-	client.LedgerClients = make(map[string]*ledger.Client)
-	return nil
+	return sdk, resmgmtClient, mspClient, nil
 }
 
+// CloseClientMap 关闭已初始化的SDK，clientMap和其中未初始化的组织可以为nil
 func CloseClientMap(clientMap map[string]*Client) {
 	for _, client := range clientMap {
-		client.SDK.Close()
+		if client == nil {
+			continue
+		}
+		client.stateLock.Lock()
+		if client.SDK != nil {
+			client.SDK.Close()
+		}
+		client.ready = false
+		client.stateLock.Unlock()
 	}
 }
 
@@ -83,28 +174,32 @@ func (client *Client) CreateChannel(channelID string) error {
 		ChannelID:         channelID,
 		ChannelConfigPath: client.ChannelConfigPath,
 		SigningIdentities: []mspctx.SigningIdentity{adminIdentity}}
+	start := time.Now()
 	_, err = client.ResmgmtClient.SaveChannel(channelReq, resmgmt.WithRetry(retry.DefaultResMgmtOpts), resmgmt.WithOrdererEndpoint(client.Org.OrdererOrgName))
+	metrics.ObserveSDK(client.Org.OrgName, "create_channel", start, err)
 	if err != nil {
 		return fmt.Errorf("创建应用通道失败: %v", err)
 	}
 
-	fmt.Println("通道已成功创建")
+	log.Infow("通道已成功创建", "org", client.Org.OrgName, "channel", channelID)
 	return nil
 }
 
 func (client *Client) JoinChannel(channelID string) error {
+	start := time.Now()
 	err := client.ResmgmtClient.JoinChannel(channelID, resmgmt.WithRetry(retry.DefaultResMgmtOpts), resmgmt.WithOrdererEndpoint(client.Org.OrdererOrgName))
+	metrics.ObserveSDK(client.Org.OrgName, "join_channel", start, err)
 	if err != nil {
-		fmt.Errorf("Peers 加入通道失败: %v", err)
-		return err
+		return fmt.Errorf("Peers 加入通道失败: %v", err)
 	}
 
-	fmt.Println("Peers 已成功加入通道")
+	log.Infow("Peers 已成功加入通道", "org", client.Org.OrgName, "channel", channelID)
 	return nil
 }
 
 func (client *Client) InstallCC(ccRequest *CCRequest) error {
-	fmt.Println("开始安装链码......")
+	ccLog := log.With("org", client.Org.OrgName, "chaincode", ccRequest.ChaincodeID, "version", ccRequest.ChaincodeVersion)
+	ccLog.Infow("开始安装链码")
 	ccPkg, err := gopackager.NewCCPackage(ccRequest.ChaincodePath, goPath)
 	if err != nil {
 		return fmt.Errorf("创建链码包失败: %v", err)
@@ -116,17 +211,20 @@ func (client *Client) InstallCC(ccRequest *CCRequest) error {
 		Version: ccRequest.ChaincodeVersion,
 		Package: ccPkg,
 	}
+	start := time.Now()
 	_, err = client.ResmgmtClient.InstallCC(installCCReq, resmgmt.WithRetry(retry.DefaultResMgmtOpts))
+	metrics.ObserveSDK(client.Org.OrgName, "install", start, err)
 	if err != nil {
 		return fmt.Errorf("安装链码失败: %v", err)
 	}
 
-	fmt.Println("指定的链码安装成功")
+	ccLog.Infow("指定的链码安装成功")
 	return nil
 }
 
 func (client *Client) InstantiateCC(ccRequest *CCRequest) error {
-	fmt.Println("开始实例化链码......")
+	ccLog := log.With("org", client.Org.OrgName, "channel", ccRequest.ChannelID, "chaincode", ccRequest.ChaincodeID, "version", ccRequest.ChaincodeVersion)
+	ccLog.Infow("开始实例化链码")
 
 	ccPolicy := cauthdsl.SignedByAnyMember([]string{client.Org.OrgMspID})
 	instantiateCCReq := resmgmt.InstantiateCCRequest{
@@ -136,17 +234,20 @@ func (client *Client) InstantiateCC(ccRequest *CCRequest) error {
 		Args:    ToBytesArgs(ccRequest.Args),
 		Policy:  ccPolicy,
 	}
+	start := time.Now()
 	_, err := client.ResmgmtClient.InstantiateCC(ccRequest.ChannelID, instantiateCCReq, resmgmt.WithRetry(retry.DefaultResMgmtOpts))
+	metrics.ObserveSDK(client.Org.OrgName, "instantiate", start, err)
 	if err != nil {
 		return fmt.Errorf("实例化链码失败: %v", err)
 	}
 
-	fmt.Println("链码实例化成功")
+	ccLog.Infow("链码实例化成功")
 	return nil
 }
 
 func (client *Client) UpgradeCC(ccRequest *CCRequest) error {
-	fmt.Println("开始升级链码......")
+	ccLog := log.With("org", client.Org.OrgName, "channel", ccRequest.ChannelID, "chaincode", ccRequest.ChaincodeID, "version", ccRequest.ChaincodeVersion)
+	ccLog.Infow("开始升级链码")
 
 	ccPolicy := cauthdsl.SignedByAnyMember([]string{client.Org.OrgMspID})
 	upgradeCCReq := resmgmt.UpgradeCCRequest{
@@ -156,12 +257,14 @@ func (client *Client) UpgradeCC(ccRequest *CCRequest) error {
 		Args:    ToBytesArgs(ccRequest.Args),
 		Policy:  ccPolicy,
 	}
+	start := time.Now()
 	_, err := client.ResmgmtClient.UpgradeCC(ccRequest.ChannelID, upgradeCCReq, resmgmt.WithRetry(retry.DefaultResMgmtOpts))
+	metrics.ObserveSDK(client.Org.OrgName, "upgrade", start, err)
 	if err != nil {
 		return fmt.Errorf("升级链码失败: %v", err)
 	}
 
-	fmt.Println("链码升级成功")
+	ccLog.Infow("链码升级成功")
 	return nil
 }
 
@@ -172,7 +275,7 @@ func (client *Client) NewChannelClient(channelClientRequest *ChannelClientReques
 		return nil, fmt.Errorf("创建应用通道客户端失败: %v", err)
 	}
 
-	fmt.Println("通道客户端创建成功，可以利用此客户端调用链码进行查询或执行事务.")
+	log.Debugw("通道客户端创建成功", "org", channelClientRequest.OrgName, "user", channelClientRequest.UserName, "channel", channelClientRequest.ChannelID)
 	return channelClient, nil
 }
 
@@ -183,10 +286,110 @@ func (client *Client) NewLedgerClient(channelClientRequest *ChannelClientRequest
 		return nil, fmt.Errorf("创建账本客户端失败: %v", err)
 	}
 
-	fmt.Println("账本客户端创建成功.")
+	log.Debugw("账本客户端创建成功", "org", channelClientRequest.OrgName, "user", channelClientRequest.UserName, "channel", channelClientRequest.ChannelID)
 	return ledgerClient, nil
 }
 
+// NewEventClient 创建事件客户端，接收完整区块以获取链码事件的payload，用于持续订阅事件
+func (client *Client) NewEventClient(channelClientRequest *ChannelClientRequest) (*event.Client, error) {
+	clientChannelContext := client.SDK.ChannelContext(channelClientRequest.ChannelID, fabsdk.WithUser(channelClientRequest.UserName), fabsdk.WithOrg(channelClientRequest.OrgName))
+	eventClient, err := event.New(clientChannelContext, event.WithBlockEvents())
+	if err != nil {
+		return nil, fmt.Errorf("创建事件客户端失败: %v", err)
+	}
+
+	log.Debugw("事件客户端创建成功", "org", channelClientRequest.OrgName, "user", channelClientRequest.UserName, "channel", channelClientRequest.ChannelID)
+	return eventClient, nil
+}
+
+// GetChannelClient 获取缓存的通道客户端，不存在时新建
+func (client *Client) GetChannelClient(channelClientRequest *ChannelClientRequest) (*channel.Client, error) {
+	key := channelClientRequest.ChannelID + channelClientRequest.OrgName + channelClientRequest.UserName
+
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if channelClient, ok := client.ChannelClients[key]; ok {
+		return channelClient, nil
+	}
+
+	channelClient, err := client.NewChannelClient(channelClientRequest)
+	if err != nil {
+		return nil, err
+	}
+	client.ChannelClients[key] = channelClient
+	return channelClient, nil
+}
+
+// GetLedgerClient 获取缓存的账本客户端，不存在时新建
+func (client *Client) GetLedgerClient(channelClientRequest *ChannelClientRequest) (*ledger.Client, error) {
+	key := channelClientRequest.ChannelID + channelClientRequest.OrgName + channelClientRequest.UserName
+
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if ledgerClient, ok := client.LedgerClients[key]; ok {
+		return ledgerClient, nil
+	}
+
+	ledgerClient, err := client.NewLedgerClient(channelClientRequest)
+	if err != nil {
+		return nil, err
+	}
+	client.LedgerClients[key] = ledgerClient
+	return ledgerClient, nil
+}
+
+// ValidateTargetPeers 校验指定的节点已在SDK配置中定义且属于该组织
+func (client *Client) ValidateTargetPeers(peers []string) error {
+	endpointConfig, err := client.endpointConfig()
+	if err != nil {
+		return err
+	}
+
+	orgPeers, _ := endpointConfig.PeersConfig(client.Org.OrgName)
+	for _, peer := range peers {
+		peerConfig, ok := endpointConfig.PeerConfig(peer)
+		if !ok {
+			return fmt.Errorf("节点【%s】未在SDK配置中定义", peer)
+		}
+
+		belongs := false
+		for _, orgPeer := range orgPeers {
+			if orgPeer.URL == peerConfig.URL {
+				belongs = true
+				break
+			}
+		}
+		if !belongs {
+			return fmt.Errorf("节点【%s】不属于【%s】组织", peer, client.Org.OrgName)
+		}
+	}
+	return nil
+}
+
+// 初始化时解析的节点配置
+func (client *Client) endpointConfig() (fab.EndpointConfig, error) {
+	client.stateLock.RLock()
+	defer client.stateLock.RUnlock()
+	if client.endpoints == nil {
+		return nil, fmt.Errorf("【%s】组织的SDK未初始化", client.Org.OrgName)
+	}
+	return client.endpoints, nil
+}
+
+// 从SDK配置中解析节点配置，只在初始化时调用
+func newEndpointConfig(orgName string, sdk *fabsdk.FabricSDK) (fab.EndpointConfig, error) {
+	configBackend, err := sdk.Config()
+	if err != nil {
+		return nil, fmt.Errorf("获取【%s】组织的SDK配置失败: %v", orgName, err)
+	}
+
+	endpointConfig, err := fabImpl.ConfigFromBackend(configBackend)
+	if err != nil {
+		return nil, fmt.Errorf("解析【%s】组织的节点配置失败: %v", orgName, err)
+	}
+	return endpointConfig, nil
+}
+
 func ToBytesArgs(args []string) [][]byte {
 	len := len(args)
 	bytesArgs := make([][]byte, len)
@@ -1,39 +1,118 @@
 package service
 
 import (
+	"context"
+	"fabric-client/logger"
+	"fabric-client/metrics"
 	"fabric-client/sdkInit"
+	"fabric-client/tracing"
+	"fmt"
 	"time"
 
 	"github.com/hyperledger/fabric-protos-go/common"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
 )
 
 var ClientMap map[string]*sdkInit.Client
 
+var log = logger.Named("service")
+
 type Setup struct {
+	ChannelID   string
+	OrgName     string
+	UserName    string
 	ChaincodeID string
 	Client      *channel.Client
 	LClient     *ledger.Client
+	Options     []channel.RequestOption // 指定节点、超时和重试等请求选项
+	RequestID   string                  // 发起调用的请求ID，用于关联日志
+	Context     context.Context         // 发起调用的context，用于链路追踪
+}
+
+// 附加了请求ID、组织、用户、通道和链码的日志
+func (setup *Setup) logger() *zap.SugaredLogger {
+	return log.With("request_id", setup.RequestID, "org", setup.OrgName, "user", setup.UserName,
+		"channel", setup.ChannelID, "chaincode", setup.ChaincodeID)
 }
 
-func (setup *Setup) Execute(fcn string, args [][]byte) (channel.Response, error) {
+func (setup *Setup) Execute(fcn string, args [][]byte) (response channel.Response, err error) {
+	ctx, span := setup.startSpan("service.execute", attribute.String("fabric.fcn", fcn))
+	defer func() { tracing.End(span, err) }()
+
 	request := channel.Request{
 		ChaincodeID: setup.ChaincodeID,
 		Fcn:         fcn,
 		Args:        args,
 	}
-	response, err := setup.Client.Execute(request)
+
+	// 与channel.Client.Execute的处理链相同，插入背书和提交计时
+	start := time.Now()
+	timer := setup.newInvokeTimer(ctx)
+	response, err = setup.Client.InvokeHandler(
+		invoke.NewProposalProcessorHandler(
+			timer.beforeEndorsement(invoke.NewEndorsementHandler(
+				invoke.NewEndorsementValidationHandler(
+					invoke.NewSignatureValidationHandler(
+						timer.afterEndorsement(timer.commit(invoke.NewCommitHandler())),
+					),
+				),
+			)),
+		),
+		request,
+		setup.Options...,
+	)
+	metrics.ObserveSDK(setup.OrgName, "exec", start, err)
+	if err == nil {
+		span.SetAttributes(attribute.String("fabric.tx_id", string(response.TransactionID)))
+	}
 	return response, err
 }
 
+// NewLedgerSetup 根据ClientMap中的组织客户端创建只包含账本客户端的Setup，用于后台任务和命令行
+func NewLedgerSetup(channelID string, orgName string, userName string) (*Setup, error) {
+	client, ok := ClientMap[orgName]
+	if !ok {
+		return nil, fmt.Errorf("组织【%s】的客户端不存在", orgName)
+	}
+	if err := client.CheckReady(); err != nil {
+		return nil, err
+	}
+
+	ledgerClient, err := client.GetLedgerClient(&sdkInit.ChannelClientRequest{
+		ChannelID: channelID,
+		OrgName:   orgName,
+		UserName:  userName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Setup{
+		ChannelID: channelID,
+		OrgName:   orgName,
+		UserName:  userName,
+		LClient:   ledgerClient,
+	}, nil
+}
+
 func (setup *Setup) QueryBlockByTxID(txID fab.TransactionID)  (*common.Block, error) {
+	_, span := setup.startSpan("service.query_block_by_tx_id", attribute.String("fabric.tx_id", string(txID)))
 	block, err :=setup.LClient.QueryBlockByTxID(txID)
+	tracing.End(span, err)
 	return block, err
 }
 
-func (setup *Setup) SetEvent(eventFilter string, eventCallbackUrl string, handler func(eventFilter string, callbackUrl string, event *fab.CCEvent)) error {
+// SetEvent 等待链码事件并回调handler，在请求结束后仍会执行，ctx不随请求取消。
+// 服务停止时不再等待，取消事件注册
+func (setup *Setup) SetEvent(eventFilter string, eventCallbackUrl string, handler func(ctx context.Context, eventFilter string, callbackUrl string, event *fab.CCEvent)) error {
+	ctx, span := setup.withContext(tracing.Detach(setup.context())).startSpan("service.set_event", attribute.String("fabric.event_filter", eventFilter))
+	defer span.End()
+
 	reg, notifier, err := setup.Client.RegisterChaincodeEvent(setup.ChaincodeID, eventFilter)
 	if err != nil {
 		return err
@@ -42,10 +121,16 @@ func (setup *Setup) SetEvent(eventFilter string, eventCallbackUrl string, handle
 
 	select {
 	case ccEvent := <-notifier:
-		handler(eventFilter, eventCallbackUrl, ccEvent)
+		metrics.EventDeliveries.WithLabelValues(metrics.EventReceived).Inc()
+		handler(ctx, eventFilter, eventCallbackUrl, ccEvent)
 		break
 	case <-time.After(time.Second * 20):
-		handler(eventFilter, eventCallbackUrl, nil)
+		metrics.EventDeliveries.WithLabelValues(metrics.EventTimeout).Inc()
+		span.SetAttributes(attribute.Bool("fabric.event_timeout", true))
+		handler(ctx, eventFilter, eventCallbackUrl, nil)
+		break
+	case <-stopping:
+		setup.logger().Infow("服务停止，取消等待链码事件", "event_filter", eventFilter)
 		break
 	}
 
@@ -59,6 +144,10 @@ func (setup *Setup) Query(fcn string, args [][]byte) (channel.Response, error) {
 		Args:        args,
 	}
 
-	response, err := setup.Client.Query(request)
+	_, span := setup.startSpan("service.query", attribute.String("fabric.fcn", fcn))
+	start := time.Now()
+	response, err := setup.Client.Query(request, setup.Options...)
+	metrics.ObserveSDK(setup.OrgName, "query", start, err)
+	tracing.End(span, err)
 	return response, err
 }
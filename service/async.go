@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"fabric-client/inits/parse"
+	"fabric-client/metrics"
+	"fabric-client/tracing"
+	"fmt"
+	"time"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 异步交易等待提交事件的默认超时时长
+const defaultCommitTimeout = 2 * time.Minute
+
+// 服务停止时不再等待提交事件
+var errCommitWaitStopped = errors.New("服务停止，不再等待交易提交事件")
+
+// 发送交易到排序节点后立即返回，在后台等待交易的提交事件
+type asyncCommitHandler struct {
+	onCommit func(txStatus *fab.TxStatusEvent, err error)
+}
+
+func (handler *asyncCommitHandler) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	txnID := requestContext.Response.TransactionID
+
+	reg, statusNotifier, err := clientContext.EventService.RegisterTxStatusEvent(string(txnID))
+	if err != nil {
+		requestContext.Error = fmt.Errorf("注册交易状态事件失败: %v", err)
+		return
+	}
+
+	tx, err := clientContext.Transactor.CreateTransaction(fab.TransactionRequest{
+		Proposal:          requestContext.Response.Proposal,
+		ProposalResponses: requestContext.Response.Responses,
+	})
+	if err != nil {
+		clientContext.EventService.Unregister(reg)
+		requestContext.Error = fmt.Errorf("创建交易失败: %v", err)
+		return
+	}
+
+	if _, err = clientContext.Transactor.SendTransaction(tx); err != nil {
+		clientContext.EventService.Unregister(reg)
+		requestContext.Error = fmt.Errorf("发送交易失败: %v", err)
+		return
+	}
+
+	timeout := parse.App.Async.CommitTimeout
+	if timeout <= 0 {
+		timeout = defaultCommitTimeout
+	}
+
+	Go(func() {
+		defer clientContext.EventService.Unregister(reg)
+		select {
+		case txStatus := <-statusNotifier:
+			handler.onCommit(txStatus, nil)
+		case <-time.After(timeout):
+			handler.onCommit(nil, fmt.Errorf("等待交易(%s)提交事件超时", txnID))
+		case <-stopping:
+			handler.onCommit(nil, errCommitWaitStopped)
+		}
+	})
+}
+
+// ExecuteAsync 背书并发送交易后立即返回交易ID，交易状态通过Tracker查询
+func (setup *Setup) ExecuteAsync(fcn string, args [][]byte) (response channel.Response, err error) {
+	ctx, span := setup.startSpan("service.execute_async", attribute.String("fabric.fcn", fcn))
+	defer func() { tracing.End(span, err) }()
+
+	request := channel.Request{
+		ChaincodeID: setup.ChaincodeID,
+		Fcn:         fcn,
+		Args:        args,
+	}
+
+	// 交易开始跟踪后才处理提交事件
+	var txID fab.TransactionID
+	committed := make(chan struct{})
+	defer close(committed)
+	timer := setup.newInvokeTimer(tracing.Detach(ctx))
+	handler := &asyncCommitHandler{
+		onCommit: func(txStatus *fab.TxStatusEvent, err error) {
+			if err == nil && txStatus.TxValidationCode != pb.TxValidationCode_VALID {
+				timer.observeCommit(fmt.Errorf("交易无效: %s", txStatus.TxValidationCode))
+			} else {
+				timer.observeCommit(err)
+			}
+			<-committed
+			setup.withContext(timer.ctx).onAsyncCommit(txID, fcn, args, txStatus, err)
+		},
+	}
+
+	start := time.Now()
+	response, err = setup.Client.InvokeHandler(
+		invoke.NewProposalProcessorHandler(
+			timer.beforeEndorsement(invoke.NewEndorsementHandler(
+				invoke.NewEndorsementValidationHandler(
+					invoke.NewSignatureValidationHandler(timer.afterEndorsement(handler)),
+				),
+			)),
+		),
+		request,
+		setup.Options...,
+	)
+	metrics.ObserveSDK(setup.OrgName, "exec_async", start, err)
+	if err != nil {
+		return response, err
+	}
+
+	txID = response.TransactionID
+	span.SetAttributes(attribute.String("fabric.tx_id", string(txID)))
+	Tracker.Track(&TxState{
+		TxID:        string(txID),
+		ChannelID:   setup.ChannelID,
+		ChaincodeID: setup.ChaincodeID,
+		Status:      TxPending,
+		Payload:     response.Payload,
+	})
+	return response, nil
+}
+
+// 异步交易提交后更新交易状态，并将交易信息加入数据库
+func (setup *Setup) onAsyncCommit(txID fab.TransactionID, fcn string, args [][]byte, txStatus *fab.TxStatusEvent, err error) {
+	if err != nil {
+		setup.onCommitUnknown(txID, fcn, args, err)
+		return
+	}
+
+	Tracker.Update(string(txID), func(state *TxState) {
+		state.ValidationCode = txStatus.TxValidationCode.String()
+		state.BlockNumber = txStatus.BlockNumber
+		if txStatus.TxValidationCode == pb.TxValidationCode_VALID {
+			state.Status = TxCommitted
+		} else {
+			state.Status = TxInvalid
+		}
+	})
+
+	state, _ := Tracker.Get(string(txID))
+	if _, err = setup.IndexTx(txID, fcn, args, state.Payload); err != nil {
+		setup.logger().Errorw("交易信息加入数据库失败", "tx_id", txID, "error", err)
+	}
+}
+
+// 未收到提交事件时查询账本确定交易状态，账本中查不到或服务正在停止时状态为unknown
+func (setup *Setup) onCommitUnknown(txID fab.TransactionID, fcn string, args [][]byte, waitErr error) {
+	if waitErr != errCommitWaitStopped {
+		txMeta, err := setup.QueryTxMeta(txID)
+		if err == nil {
+			setup.onAsyncCommit(txID, fcn, args, &fab.TxStatusEvent{
+				TxID:             string(txID),
+				TxValidationCode: txMeta.ValidationCode,
+				BlockNumber:      txMeta.BlockNumber,
+			}, nil)
+			return
+		}
+		setup.logger().Warnw("未收到提交事件且账本中查不到交易，交易状态未知", "tx_id", txID, "error", err)
+	}
+
+	Tracker.Update(string(txID), func(state *TxState) {
+		state.Status = TxUnknown
+		state.Error = waitErr.Error()
+	})
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// 从区块中解析出的交易信息
+type TxMeta struct {
+	TxID           string
+	ChannelID      string
+	Type           common.HeaderType // 交易类型，链码交易为ENDORSER_TRANSACTION
+	Seconds        int64             // 交易提案的时间戳
+	Nanos          int32
+	CreatorMSPID   string
+	Creator        string // 提交者证书的CN
+	BlockNumber    uint64
+	BlockHash      string
+	PreviousHash   string
+	TxIndex        int
+	ValidationCode pb.TxValidationCode
+}
+
+// UnixNano 返回交易时间戳的UTC纳秒值
+func (txMeta *TxMeta) UnixNano() int64 {
+	return txMeta.Seconds*int64(time.Second) + int64(txMeta.Nanos)
+}
+
+// ParseBlockTxs 解析区块中的所有交易
+func ParseBlockTxs(block *common.Block) ([]*TxMeta, error) {
+	if block == nil || block.Header == nil || block.Data == nil {
+		return nil, fmt.Errorf("区块数据不完整")
+	}
+
+	blockHash := hex.EncodeToString(BlockHeaderHash(block.Header))
+	previousHash := hex.EncodeToString(block.Header.PreviousHash)
+
+	var txFilter []byte
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		txFilter = block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+
+	txs := make([]*TxMeta, 0, len(block.Data.Data))
+	for i, data := range block.Data.Data {
+		txMeta, err := parseEnvelope(data)
+		if err != nil {
+			return nil, fmt.Errorf("解析区块%d的第%d笔交易失败: %v", block.Header.Number, i, err)
+		}
+
+		txMeta.BlockNumber = block.Header.Number
+		txMeta.BlockHash = blockHash
+		txMeta.PreviousHash = previousHash
+		txMeta.TxIndex = i
+		if i < len(txFilter) {
+			txMeta.ValidationCode = pb.TxValidationCode(txFilter[i])
+		}
+		txs = append(txs, txMeta)
+	}
+	return txs, nil
+}
+
+// FindBlockTx 在区块中查找指定交易
+func FindBlockTx(block *common.Block, txID string) (*TxMeta, error) {
+	txs, err := ParseBlockTxs(block)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, txMeta := range txs {
+		if txMeta.TxID == txID {
+			return txMeta, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: 区块%d中不存在交易%s", ErrTxNotFound, block.Header.Number, txID)
+}
+
+// BlockHeaderHash 计算区块头的hash，与fabric中区块hash的计算方式一致
+func BlockHeaderHash(header *common.BlockHeader) []byte {
+	asn1Header := struct {
+		Number       *big.Int
+		PreviousHash []byte
+		DataHash     []byte
+	}{
+		Number:       new(big.Int).SetUint64(header.Number),
+		PreviousHash: header.PreviousHash,
+		DataHash:     header.DataHash,
+	}
+
+	headerBytes, err := asn1.Marshal(asn1Header)
+	if err != nil {
+		// 以上结构的asn1编码不会失败
+		panic(err)
+	}
+	hash := sha256.Sum256(headerBytes)
+	return hash[:]
+}
+
+// 解析交易信封中的通道头和签名头
+func parseEnvelope(data []byte) (*TxMeta, error) {
+	envelope := &common.Envelope{}
+	if err := proto.Unmarshal(data, envelope); err != nil {
+		return nil, err
+	}
+
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		return nil, err
+	}
+	if payload.Header == nil {
+		return nil, fmt.Errorf("交易缺少头信息")
+	}
+
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(payload.Header.ChannelHeader, channelHeader); err != nil {
+		return nil, err
+	}
+
+	signatureHeader := &common.SignatureHeader{}
+	if err := proto.Unmarshal(payload.Header.SignatureHeader, signatureHeader); err != nil {
+		return nil, err
+	}
+
+	txMeta := &TxMeta{
+		TxID:      channelHeader.TxId,
+		ChannelID: channelHeader.ChannelId,
+		Type:      common.HeaderType(channelHeader.Type),
+		Seconds:   channelHeader.GetTimestamp().GetSeconds(),
+		Nanos:     channelHeader.GetTimestamp().GetNanos(),
+	}
+
+	identity := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(signatureHeader.Creator, identity); err != nil {
+		return nil, err
+	}
+	txMeta.CreatorMSPID = identity.Mspid
+	txMeta.Creator = certCommonName(identity.IdBytes)
+	return txMeta, nil
+}
+
+// 获取PEM证书的CN，解析失败时返回空
+func certCommonName(idBytes []byte) string {
+	block, _ := pem.Decode(idBytes)
+	if block == nil {
+		return ""
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ""
+	}
+	return cert.Subject.CommonName
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// 测试交易的创建时间
+var testTxTime = &timestamp.Timestamp{Seconds: 1600000000, Nanos: 123456789}
+
+func mustMarshal(t *testing.T, message proto.Message) []byte {
+	t.Helper()
+	data, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// 生成CN为commonName的自签名PEM证书
+func newTestCert(t *testing.T, commonName string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// 构造交易信封，data为Payload.Data，链码交易为序列化的peer.Transaction
+func newTestEnvelope(t *testing.T, txID string, headerType common.HeaderType, creator []byte, data []byte) []byte {
+	t.Helper()
+	channelHeader := &common.ChannelHeader{
+		Type:      int32(headerType),
+		TxId:      txID,
+		ChannelId: "mychannel",
+		Timestamp: testTxTime,
+	}
+	signatureHeader := &common.SignatureHeader{
+		Creator: mustMarshal(t, &msp.SerializedIdentity{Mspid: "Org1MSP", IdBytes: creator}),
+	}
+	payload := &common.Payload{
+		Header: &common.Header{
+			ChannelHeader:   mustMarshal(t, channelHeader),
+			SignatureHeader: mustMarshal(t, signatureHeader),
+		},
+		Data: data,
+	}
+	return mustMarshal(t, &common.Envelope{Payload: mustMarshal(t, payload)})
+}
+
+// 构造区块，validationCodes为各交易的验证结果
+func newTestBlock(number uint64, envelopes [][]byte, validationCodes []pb.TxValidationCode) *common.Block {
+	txFilter := make([]byte, len(validationCodes))
+	for i, code := range validationCodes {
+		txFilter[i] = byte(code)
+	}
+	metadata := make([][]byte, len(common.BlockMetadataIndex_name))
+	metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txFilter
+	return &common.Block{
+		Header:   &common.BlockHeader{Number: number, PreviousHash: []byte{0xab, 0xcd}, DataHash: []byte{0x01}},
+		Data:     &common.BlockData{Data: envelopes},
+		Metadata: &common.BlockMetadata{Metadata: metadata},
+	}
+}
+
+func TestParseBlockTxs(t *testing.T) {
+	cert := newTestCert(t, "User1@org1.example.com")
+	block := newTestBlock(5, [][]byte{
+		newTestEnvelope(t, "tx-valid", common.HeaderType_ENDORSER_TRANSACTION, cert, nil),
+		newTestEnvelope(t, "tx-mvcc", common.HeaderType_ENDORSER_TRANSACTION, cert, nil),
+		newTestEnvelope(t, "tx-config", common.HeaderType_CONFIG, []byte("not a pem"), nil),
+	}, []pb.TxValidationCode{pb.TxValidationCode_VALID, pb.TxValidationCode_MVCC_READ_CONFLICT, pb.TxValidationCode_VALID})
+
+	txs, err := ParseBlockTxs(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		txID           string
+		headerType     common.HeaderType
+		validationCode pb.TxValidationCode
+		creator        string
+	}{
+		{"tx-valid", common.HeaderType_ENDORSER_TRANSACTION, pb.TxValidationCode_VALID, "User1@org1.example.com"},
+		{"tx-mvcc", common.HeaderType_ENDORSER_TRANSACTION, pb.TxValidationCode_MVCC_READ_CONFLICT, "User1@org1.example.com"},
+		{"tx-config", common.HeaderType_CONFIG, pb.TxValidationCode_VALID, ""},
+	}
+	if len(txs) != len(expected) {
+		t.Fatalf("解析出%d笔交易，应为%d", len(txs), len(expected))
+	}
+	blockHash := hex.EncodeToString(BlockHeaderHash(block.Header))
+	for i, txMeta := range txs {
+		want := expected[i]
+		if txMeta.TxID != want.txID || txMeta.Type != want.headerType || txMeta.ValidationCode != want.validationCode || txMeta.Creator != want.creator {
+			t.Errorf("第%d笔交易为%+v，应为%+v", i, txMeta, want)
+		}
+		if txMeta.TxIndex != i || txMeta.BlockNumber != 5 || txMeta.ChannelID != "mychannel" || txMeta.CreatorMSPID != "Org1MSP" {
+			t.Errorf("第%d笔交易的位置或通道信息错误: %+v", i, txMeta)
+		}
+		if txMeta.BlockHash != blockHash || txMeta.PreviousHash != "abcd" {
+			t.Errorf("第%d笔交易的区块hash错误: %s, %s", i, txMeta.BlockHash, txMeta.PreviousHash)
+		}
+		if txMeta.UnixNano() != 1600000000123456789 {
+			t.Errorf("第%d笔交易的时间戳为%d", i, txMeta.UnixNano())
+		}
+	}
+
+	indexable := indexableTxs(txs)
+	if len(indexable) != 1 || indexable[0].TxID != "tx-valid" {
+		t.Errorf("应加入索引的交易只有tx-valid，实际为%v", indexable)
+	}
+}
+
+func TestParseBlockTxsInvalid(t *testing.T) {
+	cases := map[string]*common.Block{
+		"nil block":    nil,
+		"no header":    {Data: &common.BlockData{}},
+		"no data":      {Header: &common.BlockHeader{}},
+		"bad envelope": newTestBlock(1, [][]byte{[]byte("garbage")}, nil),
+	}
+	for name, block := range cases {
+		if _, err := ParseBlockTxs(block); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+}
+
+func TestFindBlockTx(t *testing.T) {
+	block := newTestBlock(3, [][]byte{
+		newTestEnvelope(t, "tx1", common.HeaderType_ENDORSER_TRANSACTION, nil, nil),
+	}, []pb.TxValidationCode{pb.TxValidationCode_VALID})
+
+	txMeta, err := FindBlockTx(block, "tx1")
+	if err != nil || txMeta.TxID != "tx1" {
+		t.Fatalf("FindBlockTx(tx1) = %v, %v", txMeta, err)
+	}
+	if _, err = FindBlockTx(block, "tx2"); !errors.Is(err, ErrTxNotFound) {
+		t.Errorf("查找不存在的交易应返回ErrTxNotFound，实际为%v", err)
+	}
+}
+
+func TestIsTxNotFound(t *testing.T) {
+	cases := []struct {
+		message  string
+		notFound bool
+	}{
+		{"Failed to get block for txID tx1, error Entry not found in index", true},
+		{"Transaction processing for endorser [peer0:7051]: Chaincode status Code: (500) UNKNOWN. Description: Failed to get block for txID tx1, error no such transaction ID [tx1] in index", true},
+		{"connection error: desc = \"transport: error while dialing: dial tcp 127.0.0.1:7051: connect: connection refused\"", false},
+		{"context deadline exceeded", false},
+	}
+	for _, c := range cases {
+		if got := isTxNotFound(errors.New(c.message)); got != c.notFound {
+			t.Errorf("isTxNotFound(%q) = %v，应为%v", c.message, got, c.notFound)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fabric-client/inits/parse"
+	"fabric-client/sdkInit"
+	"time"
+)
+
+// 重试初始化失败组织的默认间隔
+const defaultInitRetryInterval = 30 * time.Second
+
+// StartClientInitRetry 定期重试初始化不可用的组织客户端，全部可用后退出
+func StartClientInitRetry() {
+	if !hasUnavailableClient() {
+		return
+	}
+
+	interval := parse.App.SDK.InitRetryInterval
+	if interval <= 0 {
+		interval = defaultInitRetryInterval
+	}
+
+	Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if sdkInit.RetryInit(ClientMap) == 0 {
+					log.Infow("所有组织客户端已可用")
+					return
+				}
+			case <-stopping:
+				return
+			}
+		}
+	})
+}
+
+func hasUnavailableClient() bool {
+	for _, client := range ClientMap {
+		if !client.Ready() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"strings"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+)
+
+// SDK错误的分类
+const (
+	ErrorMVCCConflict        = "mvcc_conflict"        // 读写集冲突，交易验证失败，可以重试
+	ErrorTimeout             = "timeout"              // 等待背书、排序或提交事件超时
+	ErrorPolicyFailure       = "policy_failure"       // 不满足背书策略或没有访问权限
+	ErrorEndorsementMismatch = "endorsement_mismatch" // 各节点的背书结果不一致
+)
+
+// ClassifyError 根据SDK返回的状态和错误信息对错误分类，无法分类时返回空字符串
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	if s, ok := status.FromError(err); ok {
+		switch s.Group {
+		case status.EndorserClientStatus:
+			if s.Code == status.EndorsementMismatch.ToInt32() {
+				return ErrorEndorsementMismatch
+			}
+		case status.EventServerStatus:
+			switch pb.TxValidationCode(s.Code) {
+			case pb.TxValidationCode_MVCC_READ_CONFLICT, pb.TxValidationCode_PHANTOM_READ_CONFLICT:
+				return ErrorMVCCConflict
+			case pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE:
+				return ErrorPolicyFailure
+			}
+		case status.ClientStatus:
+			if s.Code == status.Timeout.ToInt32() {
+				return ErrorTimeout
+			}
+		}
+	}
+
+	// 异步提交和重试后的错误只保留了错误信息
+	message := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(message, "mvcc_read_conflict") || strings.Contains(message, "phantom_read_conflict"):
+		return ErrorMVCCConflict
+	case strings.Contains(message, "endorsement_policy_failure") || strings.Contains(message, "access denied") ||
+		strings.Contains(message, "policy") && strings.Contains(message, "not satisfied"):
+		return ErrorPolicyFailure
+	case strings.Contains(message, "payloads do not match"):
+		return ErrorEndorsementMismatch
+	case strings.Contains(message, "timeout") || strings.Contains(message, "timed out") ||
+		strings.Contains(message, "deadline exceeded") || strings.Contains(message, "超时"):
+		return ErrorTimeout
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"nil", nil, ""},
+		{"endorsement mismatch status", status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(), "ProposalResponsePayloads do not match", nil), ErrorEndorsementMismatch},
+		{"mvcc status", status.New(status.EventServerStatus, int32(pb.TxValidationCode_MVCC_READ_CONFLICT), "received invalid transaction", nil), ErrorMVCCConflict},
+		{"phantom read status", status.New(status.EventServerStatus, int32(pb.TxValidationCode_PHANTOM_READ_CONFLICT), "received invalid transaction", nil), ErrorMVCCConflict},
+		{"policy status", status.New(status.EventServerStatus, int32(pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE), "received invalid transaction", nil), ErrorPolicyFailure},
+		{"timeout status", status.New(status.ClientStatus, status.Timeout.ToInt32(), "request timed out or been cancelled", nil), ErrorTimeout},
+		{"wrapped status", fmt.Errorf("执行链码失败: %w", status.New(status.ClientStatus, status.Timeout.ToInt32(), "request", nil)), ErrorTimeout},
+		{"other event status", status.New(status.EventServerStatus, int32(pb.TxValidationCode_BAD_PAYLOAD), "received invalid transaction", nil), ""},
+		{"other client status", status.New(status.ClientStatus, status.ConnectionFailed.ToInt32(), "connection failed", nil), ""},
+		{"mvcc message", errors.New("transaction returned with failure: MVCC_READ_CONFLICT"), ErrorMVCCConflict},
+		{"policy message", errors.New("transaction returned with failure: ENDORSEMENT_POLICY_FAILURE"), ErrorPolicyFailure},
+		{"access denied message", errors.New("access denied: channel [mychannel] creator org [Org3MSP]"), ErrorPolicyFailure},
+		{"signature policy message", errors.New("signature set did not satisfy policy: policy not satisfied"), ErrorPolicyFailure},
+		{"payload mismatch message", errors.New("ProposalResponsePayloads do not match"), ErrorEndorsementMismatch},
+		{"deadline message", errors.New("context deadline exceeded"), ErrorTimeout},
+		{"chinese timeout message", errors.New("等待交易提交超时"), ErrorTimeout},
+		{"unknown message", errors.New("chaincode error: asset not found"), ""},
+	}
+	for _, c := range cases {
+		if got := ClassifyError(c.err); got != c.expected {
+			t.Errorf("%s: ClassifyError = %q，应为%q", c.name, got, c.expected)
+		}
+	}
+}
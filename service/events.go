@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fabric-client/metrics"
+	"fmt"
+	"regexp"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// 事件服务断开时事件通道被关闭
+var errEventsClosed = errors.New("事件服务连接已断开")
+
+// SubscribeChaincodeEvents 持续接收chaincodeID中事件名匹配eventFilter的链码事件并调用handler。
+// 链码事件从完整区块中解析，不占用事件服务上按链码和过滤条件的注册，多个订阅可以使用相同的过滤条件。
+// ctx取消或服务停止时返回nil，handler返回错误或事件服务断开时返回错误
+func SubscribeChaincodeEvents(ctx context.Context, eventClient *event.Client, chaincodeID string, eventFilter string, handler func(*fab.CCEvent) error) error {
+	filter, err := regexp.Compile(eventFilter)
+	if err != nil {
+		return fmt.Errorf("事件过滤条件不是合法的正则表达式: %v", err)
+	}
+
+	reg, notifier, err := eventClient.RegisterBlockEvent()
+	if err != nil {
+		return fmt.Errorf("注册区块事件失败: %v", err)
+	}
+	defer eventClient.Unregister(reg)
+
+	for {
+		select {
+		case blockEvent, ok := <-notifier:
+			if !ok {
+				return errEventsClosed
+			}
+			ccEvents, err := parseChaincodeEvents(blockEvent.Block)
+			if err != nil {
+				log.Warnw("解析区块中的链码事件失败", "error", err)
+				continue
+			}
+			for _, ccEvent := range ccEvents {
+				if ccEvent.ChaincodeID != chaincodeID || !filter.MatchString(ccEvent.EventName) {
+					continue
+				}
+				ccEvent.SourceURL = blockEvent.SourceURL
+				metrics.EventDeliveries.WithLabelValues(metrics.EventReceived).Inc()
+				if err := handler(ccEvent); err != nil {
+					return err
+				}
+			}
+		case <-ctx.Done():
+			return nil
+		case <-stopping:
+			return nil
+		}
+	}
+}
+
+// SubscribeBlockEvents 持续接收过滤后的区块事件并调用handler，只包含交易ID和验证结果，返回规则与SubscribeChaincodeEvents相同
+func SubscribeBlockEvents(ctx context.Context, eventClient *event.Client, handler func(*fab.FilteredBlockEvent) error) error {
+	reg, notifier, err := eventClient.RegisterFilteredBlockEvent()
+	if err != nil {
+		return fmt.Errorf("注册区块事件失败: %v", err)
+	}
+	defer eventClient.Unregister(reg)
+
+	for {
+		select {
+		case blockEvent, ok := <-notifier:
+			if !ok {
+				return errEventsClosed
+			}
+			if err := handler(blockEvent); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		case <-stopping:
+			return nil
+		}
+	}
+}
+
+// 解析区块中验证通过的链码交易设置的链码事件
+func parseChaincodeEvents(block *common.Block) ([]*fab.CCEvent, error) {
+	txs, err := ParseBlockTxs(block)
+	if err != nil {
+		return nil, err
+	}
+
+	ccEvents := make([]*fab.CCEvent, 0)
+	for _, txMeta := range txs {
+		if txMeta.Type != common.HeaderType_ENDORSER_TRANSACTION || txMeta.ValidationCode != pb.TxValidationCode_VALID {
+			continue
+		}
+
+		events, err := parseTxChaincodeEvents(block.Data.Data[txMeta.TxIndex])
+		if err != nil {
+			return nil, fmt.Errorf("解析交易%s的链码事件失败: %v", txMeta.TxID, err)
+		}
+		for _, ccEvent := range events {
+			ccEvents = append(ccEvents, &fab.CCEvent{
+				TxID:        txMeta.TxID,
+				ChaincodeID: ccEvent.ChaincodeId,
+				EventName:   ccEvent.EventName,
+				Payload:     ccEvent.Payload,
+				BlockNumber: txMeta.BlockNumber,
+			})
+		}
+	}
+	return ccEvents, nil
+}
+
+// 解析交易信封中各背书动作设置的链码事件
+func parseTxChaincodeEvents(data []byte) ([]*pb.ChaincodeEvent, error) {
+	envelope := &common.Envelope{}
+	if err := proto.Unmarshal(data, envelope); err != nil {
+		return nil, err
+	}
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		return nil, err
+	}
+	transaction := &pb.Transaction{}
+	if err := proto.Unmarshal(payload.Data, transaction); err != nil {
+		return nil, err
+	}
+
+	ccEvents := make([]*pb.ChaincodeEvent, 0)
+	for _, action := range transaction.Actions {
+		actionPayload := &pb.ChaincodeActionPayload{}
+		if err := proto.Unmarshal(action.Payload, actionPayload); err != nil {
+			return nil, err
+		}
+		if actionPayload.Action == nil {
+			continue
+		}
+
+		responsePayload := &pb.ProposalResponsePayload{}
+		if err := proto.Unmarshal(actionPayload.Action.ProposalResponsePayload, responsePayload); err != nil {
+			return nil, err
+		}
+		chaincodeAction := &pb.ChaincodeAction{}
+		if err := proto.Unmarshal(responsePayload.Extension, chaincodeAction); err != nil {
+			return nil, err
+		}
+		if len(chaincodeAction.Events) == 0 {
+			continue
+		}
+
+		ccEvent := &pb.ChaincodeEvent{}
+		if err := proto.Unmarshal(chaincodeAction.Events, ccEvent); err != nil {
+			return nil, err
+		}
+		ccEvents = append(ccEvents, ccEvent)
+	}
+	return ccEvents, nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// 构造链码交易的Payload.Data，每个事件对应一个背书动作，nil为没有设置事件的动作
+func newTestTransaction(t *testing.T, events ...*pb.ChaincodeEvent) []byte {
+	t.Helper()
+	transaction := &pb.Transaction{}
+	for _, event := range events {
+		chaincodeAction := &pb.ChaincodeAction{}
+		if event != nil {
+			chaincodeAction.Events = mustMarshal(t, event)
+		}
+		responsePayload := &pb.ProposalResponsePayload{Extension: mustMarshal(t, chaincodeAction)}
+		actionPayload := &pb.ChaincodeActionPayload{Action: &pb.ChaincodeEndorsedAction{ProposalResponsePayload: mustMarshal(t, responsePayload)}}
+		transaction.Actions = append(transaction.Actions, &pb.TransactionAction{Payload: mustMarshal(t, actionPayload)})
+	}
+	return mustMarshal(t, transaction)
+}
+
+func TestParseChaincodeEvents(t *testing.T) {
+	transfer := &pb.ChaincodeEvent{ChaincodeId: "mycc", TxId: "tx1", EventName: "transfer", Payload: []byte("a->b")}
+	audit := &pb.ChaincodeEvent{ChaincodeId: "mycc", TxId: "tx1", EventName: "audit", Payload: []byte("ok")}
+	rejected := &pb.ChaincodeEvent{ChaincodeId: "mycc", TxId: "tx2", EventName: "transfer"}
+
+	block := newTestBlock(8, [][]byte{
+		newTestEnvelope(t, "tx1", common.HeaderType_ENDORSER_TRANSACTION, nil, newTestTransaction(t, transfer, nil, audit)),
+		newTestEnvelope(t, "tx2", common.HeaderType_ENDORSER_TRANSACTION, nil, newTestTransaction(t, rejected)),
+		newTestEnvelope(t, "tx3", common.HeaderType_ENDORSER_TRANSACTION, nil, newTestTransaction(t, nil)),
+		newTestEnvelope(t, "tx4", common.HeaderType_CONFIG, nil, []byte("not a transaction")),
+	}, []pb.TxValidationCode{pb.TxValidationCode_VALID, pb.TxValidationCode_MVCC_READ_CONFLICT, pb.TxValidationCode_VALID, pb.TxValidationCode_VALID})
+
+	ccEvents, err := parseChaincodeEvents(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 只返回验证通过的链码交易中的事件，按背书动作的顺序
+	expected := []*fab.CCEvent{
+		{TxID: "tx1", ChaincodeID: "mycc", EventName: "transfer", Payload: []byte("a->b"), BlockNumber: 8},
+		{TxID: "tx1", ChaincodeID: "mycc", EventName: "audit", Payload: []byte("ok"), BlockNumber: 8},
+	}
+	if !reflect.DeepEqual(ccEvents, expected) {
+		t.Errorf("链码事件为%+v，应为%+v", ccEvents, expected)
+	}
+}
+
+func TestParseChaincodeEventsInvalid(t *testing.T) {
+	cases := map[string]*common.Block{
+		"bad block": {Header: &common.BlockHeader{}},
+		"bad transaction": newTestBlock(1, [][]byte{
+			newTestEnvelope(t, "tx1", common.HeaderType_ENDORSER_TRANSACTION, nil, []byte{0xff}),
+		}, []pb.TxValidationCode{pb.TxValidationCode_VALID}),
+		"bad action": newTestBlock(1, [][]byte{
+			newTestEnvelope(t, "tx1", common.HeaderType_ENDORSER_TRANSACTION, nil,
+				mustMarshal(t, &pb.Transaction{Actions: []*pb.TransactionAction{{Payload: []byte{0xff}}}})),
+		}, []pb.TxValidationCode{pb.TxValidationCode_VALID}),
+	}
+	for name, block := range cases {
+		if _, err := parseChaincodeEvents(block); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+}
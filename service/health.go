@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fabric-client/db"
+	"fabric-client/inits/parse"
+	"fabric-client/sdkInit"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 就绪检查默认配置
+const (
+	defaultReadinessCacheTTL = 10 * time.Second
+	defaultProbeTimeout      = 5 * time.Second
+)
+
+// 单项检查结果
+type CheckResult struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// 组织的就绪检查结果，Client为初始化状态，Channels为配置通道的加入情况，
+// SDK可用且至少一个通道已加入时Serving为true，任一检查未通过时Degraded为true
+type OrgReadiness struct {
+	OrgName  string                 `json:"orgName"`
+	Client   sdkInit.ClientStatus   `json:"client"`
+	SDK      CheckResult            `json:"sdk"`
+	Channels map[string]CheckResult `json:"channels"`
+	Serving  bool                   `json:"serving"`
+	Degraded bool                   `json:"degraded"`
+}
+
+// 就绪检查报告，至少一个组织可以提供服务时Ready为true。Degraded为检查未通过的组织，主库不可用时包含database，
+// 主库不可用时链码执行仍可通过本地outbox延后写入索引，不影响就绪。CheckedAt为实际检查的时间，缓存期内返回同一份报告
+type ReadinessReport struct {
+	Ready     bool            `json:"ready"`
+	Database  CheckResult     `json:"database"`
+	Orgs      []*OrgReadiness `json:"orgs"`
+	Degraded  []string        `json:"degraded"`
+	CheckedAt int64           `json:"checkedAt"`
+}
+
+// 主库检查未通过时在Degraded中的名称
+const DatabaseCheck = "database"
+
+var readiness struct {
+	lock   sync.Mutex
+	report *ReadinessReport
+}
+
+// ProbeTimeout 连接节点和查询的超时时长
+func ProbeTimeout() time.Duration {
+	if parse.App.Health.Timeout > 0 {
+		return parse.App.Health.Timeout
+	}
+	return defaultProbeTimeout
+}
+
+func readinessCacheTTL() time.Duration {
+	if parse.App.Health.CacheTTL > 0 {
+		return parse.App.Health.CacheTTL
+	}
+	return defaultReadinessCacheTTL
+}
+
+// Readiness 返回缓存的就绪检查报告，缓存过期时重新检查，并发请求只检查一次
+func Readiness() *ReadinessReport {
+	readiness.lock.Lock()
+	defer readiness.lock.Unlock()
+
+	if readiness.report != nil && time.Since(time.Unix(readiness.report.CheckedAt, 0)) < readinessCacheTTL() {
+		return readiness.report
+	}
+	readiness.report = checkReadiness()
+	return readiness.report
+}
+
+// 检查主库连接和各组织的SDK连通性、通道加入情况，部分组织不可用时不影响其他组织提供服务
+func checkReadiness() *ReadinessReport {
+	report := &ReadinessReport{Orgs: make([]*OrgReadiness, 0, len(ClientMap)), Degraded: make([]string, 0)}
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		report.Database = checkDatabase()
+	}()
+	for _, client := range ClientMap {
+		wg.Add(1)
+		go func(client *sdkInit.Client) {
+			defer wg.Done()
+			orgReadiness := checkOrgReadiness(client)
+
+			lock.Lock()
+			defer lock.Unlock()
+			report.Orgs = append(report.Orgs, orgReadiness)
+		}(client)
+	}
+	wg.Wait()
+
+	sort.Slice(report.Orgs, func(i, j int) bool { return report.Orgs[i].OrgName < report.Orgs[j].OrgName })
+	summarizeReadiness(report)
+	report.CheckedAt = time.Now().Unix()
+	if !report.Ready {
+		log.Warnw("就绪检查未通过", "database", report.Database.OK, "degraded", report.Degraded)
+	} else if len(report.Degraded) > 0 {
+		log.Warnw("部分检查未通过", "database", report.Database.OK, "degraded", report.Degraded)
+	}
+	return report
+}
+
+// 在探测超时内ping主库，避免数据库无响应时阻塞就绪检查
+func checkDatabase() CheckResult {
+	ctx, cancel := context.WithTimeout(context.Background(), ProbeTimeout())
+	defer cancel()
+	if err := db.MasterEngine().PingContext(ctx); err != nil {
+		return CheckResult{Error: err.Error()}
+	}
+	return CheckResult{OK: true}
+}
+
+// 根据各项检查结果设置组织和整体的状态
+func summarizeReadiness(report *ReadinessReport) {
+	if !report.Database.OK {
+		report.Degraded = append(report.Degraded, DatabaseCheck)
+	}
+	serving := false
+	for _, orgReadiness := range report.Orgs {
+		joined := len(orgReadiness.Channels) == 0
+		orgReadiness.Degraded = !orgReadiness.SDK.OK
+		for _, result := range orgReadiness.Channels {
+			if result.OK {
+				joined = true
+			} else {
+				orgReadiness.Degraded = true
+			}
+		}
+		orgReadiness.Serving = orgReadiness.SDK.OK && joined
+		if orgReadiness.Serving {
+			serving = true
+		}
+		if orgReadiness.Degraded {
+			report.Degraded = append(report.Degraded, orgReadiness.OrgName)
+		}
+	}
+	report.Ready = serving
+}
+
+// 任一peer能响应查询即认为SDK可用，配置的通道至少有一个peer已加入
+func checkOrgReadiness(client *sdkInit.Client) *OrgReadiness {
+	orgReadiness := &OrgReadiness{OrgName: client.Org.OrgName, Client: client.Status(), Channels: make(map[string]CheckResult)}
+	joined, err := client.QueryJoinedChannels(ProbeTimeout())
+	if err != nil {
+		orgReadiness.SDK = CheckResult{Error: err.Error()}
+		for _, channelID := range client.Channels {
+			orgReadiness.Channels[channelID] = CheckResult{Error: "SDK不可用"}
+		}
+		return orgReadiness
+	}
+	orgReadiness.SDK = CheckResult{OK: true}
+
+	for _, channelID := range client.Channels {
+		result := CheckResult{Error: fmt.Sprintf("【%s】组织的节点未加入通道%s", client.Org.OrgName, channelID)}
+		for _, channels := range joined {
+			if containsString(channels, channelID) {
+				result = CheckResult{OK: true}
+				break
+			}
+		}
+		orgReadiness.Channels[channelID] = result
+	}
+	return orgReadiness
+}
+
+// Diagnose 返回各组织的节点诊断信息，按组织名排序
+func Diagnose() []*sdkInit.OrgDiagnostics {
+	diagnostics := make([]*sdkInit.OrgDiagnostics, 0, len(ClientMap))
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for _, client := range ClientMap {
+		wg.Add(1)
+		go func(client *sdkInit.Client) {
+			defer wg.Done()
+			orgDiagnostics := client.Diagnose(ProbeTimeout())
+
+			lock.Lock()
+			defer lock.Unlock()
+			diagnostics = append(diagnostics, orgDiagnostics)
+		}(client)
+	}
+	wg.Wait()
+
+	sort.Slice(diagnostics, func(i, j int) bool { return diagnostics[i].OrgName < diagnostics[j].OrgName })
+	return diagnostics
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeReadiness(t *testing.T) {
+	ok := CheckResult{OK: true}
+	failed := CheckResult{Error: "failed"}
+	cases := []struct {
+		name     string
+		database CheckResult
+		orgs     []*OrgReadiness
+		ready    bool
+		degraded []string
+	}{
+		{
+			name:     "all orgs serving",
+			database: ok,
+			orgs: []*OrgReadiness{
+				{OrgName: "Org1", SDK: ok, Channels: map[string]CheckResult{"ch1": ok}},
+				{OrgName: "Org2", SDK: ok, Channels: map[string]CheckResult{}},
+			},
+			ready:    true,
+			degraded: []string{},
+		},
+		{
+			name:     "one org down",
+			database: ok,
+			orgs: []*OrgReadiness{
+				{OrgName: "Org1", SDK: failed, Channels: map[string]CheckResult{"ch1": failed}},
+				{OrgName: "Org2", SDK: ok, Channels: map[string]CheckResult{"ch1": ok}},
+			},
+			ready:    true,
+			degraded: []string{"Org1"},
+		},
+		{
+			name:     "one channel not joined",
+			database: ok,
+			orgs: []*OrgReadiness{
+				{OrgName: "Org1", SDK: ok, Channels: map[string]CheckResult{"ch1": ok, "ch2": failed}},
+			},
+			ready:    true,
+			degraded: []string{"Org1"},
+		},
+		{
+			name:     "no org serving",
+			database: ok,
+			orgs: []*OrgReadiness{
+				{OrgName: "Org1", SDK: failed, Channels: map[string]CheckResult{"ch1": failed}},
+				{OrgName: "Org2", SDK: ok, Channels: map[string]CheckResult{"ch1": failed}},
+			},
+			ready:    false,
+			degraded: []string{"Org1", "Org2"},
+		},
+		{
+			name:     "database down",
+			database: failed,
+			orgs: []*OrgReadiness{
+				{OrgName: "Org1", SDK: ok, Channels: map[string]CheckResult{"ch1": ok}},
+			},
+			ready:    true,
+			degraded: []string{DatabaseCheck},
+		},
+		{
+			name:     "database and orgs down",
+			database: failed,
+			orgs: []*OrgReadiness{
+				{OrgName: "Org1", SDK: failed, Channels: map[string]CheckResult{"ch1": failed}},
+			},
+			ready:    false,
+			degraded: []string{DatabaseCheck, "Org1"},
+		},
+	}
+	for _, c := range cases {
+		report := &ReadinessReport{Database: c.database, Orgs: c.orgs, Degraded: make([]string, 0)}
+		summarizeReadiness(report)
+		if report.Ready != c.ready || !reflect.DeepEqual(report.Degraded, c.degraded) {
+			t.Errorf("%s: ready=%v degraded=%v，应为ready=%v degraded=%v", c.name, report.Ready, report.Degraded, c.ready, c.degraded)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fabric-client/models"
+	"fmt"
+	"strings"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// 账本中确定不存在交易时QueryTxMeta返回的错误，其他错误可能是节点或网络的临时故障
+var ErrTxNotFound = errors.New("账本中不存在交易")
+
+// 节点在区块索引中查不到交易时返回的错误信息，分别为fabric 1.4和2.x
+var txNotFoundMessages = []string{"entry not found in index", "no such transaction id"}
+
+// QueryTxMeta 根据交易ID查询所在区块，从区块中解析交易信息，账本中不存在时返回的错误包装ErrTxNotFound
+func (setup *Setup) QueryTxMeta(txID fab.TransactionID) (*TxMeta, error) {
+	block, err := setup.QueryBlockByTxID(txID)
+	if err != nil {
+		if isTxNotFound(err) {
+			return nil, fmt.Errorf("%w: %v", ErrTxNotFound, err)
+		}
+		return nil, err
+	}
+	return FindBlockTx(block, string(txID))
+}
+
+func isTxNotFound(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, notFound := range txNotFoundMessages {
+		if strings.Contains(message, notFound) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewBlockTXInfo 根据交易信息生成要加入数据库的区块交易信息
+func NewBlockTXInfo(txMeta *TxMeta) *models.BlockTXInfo {
+	blockTXInfo := new(models.BlockTXInfo)
+	blockTXInfo.Number = txMeta.BlockNumber
+	blockTXInfo.PreviousHash = txMeta.PreviousHash
+	blockTXInfo.TxId = txMeta.TxID
+	blockTXInfo.Timestamp = txMeta.Seconds
+	blockTXInfo.ChannelId = txMeta.ChannelID
+	blockTXInfo.Creator = txMeta.CreatorMSPID + "/" + txMeta.Creator
+	blockTXInfo.TimestampNs = txMeta.UnixNano()
+	return blockTXInfo
+}
+
+// NewTxHistory 根据交易信息和调用参数生成交易历史
+func (setup *Setup) NewTxHistory(txMeta *TxMeta, fcn string, args [][]byte, payload []byte) *models.TxHistory {
+	stringArgs := make([]string, len(args))
+	for i, arg := range args {
+		stringArgs[i] = string(arg)
+	}
+	argsJson, _ := json.Marshal(stringArgs)
+
+	status := TxCommitted
+	if txMeta.ValidationCode != pb.TxValidationCode_VALID {
+		status = TxInvalid
+	}
+
+	return &models.TxHistory{
+		TxId:           txMeta.TxID,
+		ChannelId:      txMeta.ChannelID,
+		ChaincodeId:    setup.ChaincodeID,
+		Fcn:            fcn,
+		Args:           string(argsJson),
+		OrgName:        setup.OrgName,
+		UserName:       setup.UserName,
+		Creator:        txMeta.CreatorMSPID + "/" + txMeta.Creator,
+		Status:         status,
+		ValidationCode: txMeta.ValidationCode.String(),
+		BlockNumber:    txMeta.BlockNumber,
+		BlockHash:      txMeta.BlockHash,
+		PreviousHash:   txMeta.PreviousHash,
+		Payload:        payload,
+		Timestamp:      txMeta.Seconds,
+		TimestampNs:    txMeta.UnixNano(),
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"fabric-client/inits/parse"
+	"fabric-client/models"
+	"time"
+)
+
+// 幂等记录默认保留时长
+const defaultIdempotencyRetention = 24 * time.Hour
+
+// IdempotencyRetention 返回配置的幂等记录保留时长
+func IdempotencyRetention() time.Duration {
+	if parse.App.Idempotency.Retention > 0 {
+		return parse.App.Idempotency.Retention
+	}
+	return defaultIdempotencyRetention
+}
+
+// StartIdempotencyCleaner 定期清理过期的幂等记录
+func StartIdempotencyCleaner() {
+	interval := parse.App.Idempotency.CleanInterval
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				before := time.Now().Add(-IdempotencyRetention()).Unix()
+				count, err := models.DeleteIdempotencyRecordBefore(context.Background(), before)
+				if err != nil {
+					log.Errorw("清理过期幂等记录失败", "error", err)
+					continue
+				}
+				if count > 0 {
+					log.Infow("已清理过期幂等记录", "count", count)
+				}
+			case <-stopping:
+				return
+			}
+		}
+	})
+}
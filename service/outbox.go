@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fabric-client/inits/parse"
+	"fabric-client/models"
+	"fabric-client/tracing"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// outbox默认配置
+const (
+	defaultOutboxDir           = "data/outbox"
+	defaultOutboxRetryInterval = 30 * time.Second
+)
+
+// 待写入数据库的交易索引，区块信息为空时需要重新查询账本
+type OutboxEntry struct {
+	TxID        string
+	ChannelID   string
+	OrgName     string
+	UserName    string
+	ChaincodeID string
+	Fcn         string
+	Args        [][]byte
+	Payload     []byte
+
+	BlockTXInfo *models.BlockTXInfo
+	TxHistory   *models.TxHistory
+
+	Attempts  int
+	LastError string
+	CreatedAt int64
+}
+
+// 本地文件outbox，每个交易一个文件，数据库恢复后由后台重试写入
+type Outbox struct {
+	dir  string
+	lock sync.Mutex
+}
+
+var IndexOutbox = &Outbox{dir: defaultOutboxDir}
+
+// StartOutbox 创建outbox目录并启动后台重试
+func StartOutbox() error {
+	if parse.App.Outbox.Dir != "" {
+		IndexOutbox.dir = parse.App.Outbox.Dir
+	}
+	if err := os.MkdirAll(IndexOutbox.dir, 0755); err != nil {
+		return fmt.Errorf("创建outbox目录失败: %v", err)
+	}
+
+	interval := parse.App.Outbox.RetryInterval
+	if interval <= 0 {
+		interval = defaultOutboxRetryInterval
+	}
+
+	Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				IndexOutbox.Flush()
+			case <-stopping:
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// IndexTx 将已提交的交易加入数据库，失败时写入outbox由后台重试。
+// deferred为true表示索引已延后，err不为nil表示写入outbox也失败了
+func (setup *Setup) IndexTx(txID fab.TransactionID, fcn string, args [][]byte, payload []byte) (deferred bool, err error) {
+	ctx, span := setup.startSpan("service.index_tx", attribute.String("fabric.tx_id", string(txID)))
+	defer func() {
+		span.SetAttributes(attribute.Bool("index.deferred", deferred))
+		tracing.End(span, err)
+	}()
+	setup = setup.withContext(ctx)
+
+	entry := &OutboxEntry{
+		TxID:        string(txID),
+		ChannelID:   setup.ChannelID,
+		OrgName:     setup.OrgName,
+		UserName:    setup.UserName,
+		ChaincodeID: setup.ChaincodeID,
+		Fcn:         fcn,
+		Args:        args,
+		Payload:     payload,
+		CreatedAt:   time.Now().Unix(),
+	}
+
+	indexErr := setup.fillOutboxEntry(entry)
+	if indexErr == nil {
+		indexErr = models.CreateTxRecords(ctx, entry.BlockTXInfo, entry.TxHistory)
+		if indexErr == nil {
+			return false, nil
+		}
+	}
+
+	setup.logger().Warnw("交易加入数据库失败，写入outbox稍后重试", "tx_id", txID, "error", indexErr)
+	entry.LastError = indexErr.Error()
+	if err := IndexOutbox.Put(entry); err != nil {
+		setup.logger().Errorw("交易写入outbox失败", "tx_id", txID, "error", err)
+		return true, err
+	}
+	return true, nil
+}
+
+// 查询账本并生成要写入数据库的记录
+func (setup *Setup) fillOutboxEntry(entry *OutboxEntry) error {
+	txMeta, err := setup.QueryTxMeta(fab.TransactionID(entry.TxID))
+	if err != nil {
+		return err
+	}
+
+	if txMeta.ValidationCode == pb.TxValidationCode_VALID {
+		entry.BlockTXInfo = NewBlockTXInfo(txMeta)
+	}
+	entry.TxHistory = setup.NewTxHistory(txMeta, entry.Fcn, entry.Args, entry.Payload)
+	return nil
+}
+
+// Put 将记录原子写入outbox文件
+func (outbox *Outbox) Put(entry *OutboxEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	outbox.lock.Lock()
+	defer outbox.lock.Unlock()
+
+	tmpFile, err := ioutil.TempFile(outbox.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err = tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), outbox.path(entry.TxID))
+}
+
+// Pending 返回outbox中未写入数据库的记录数
+func (outbox *Outbox) Pending() int {
+	files, _ := filepath.Glob(filepath.Join(outbox.dir, "*.json"))
+	return len(files)
+}
+
+// Flush 重试写入outbox中的所有记录，成功后删除文件
+func (outbox *Outbox) Flush() {
+	files, err := filepath.Glob(filepath.Join(outbox.dir, "*.json"))
+	if err != nil {
+		log.Errorw("读取outbox目录失败", "dir", outbox.dir, "error", err)
+		return
+	}
+
+	for _, file := range files {
+		entry, err := readOutboxEntry(file)
+		if err != nil {
+			log.Errorw("读取outbox文件失败", "file", file, "error", err)
+			continue
+		}
+
+		if err = outbox.retry(entry); err != nil {
+			entry.Attempts++
+			entry.LastError = err.Error()
+			if err = outbox.Put(entry); err != nil {
+				log.Errorw("更新outbox文件失败", "file", file, "tx_id", entry.TxID, "error", err)
+			}
+			continue
+		}
+
+		outbox.lock.Lock()
+		os.Remove(file)
+		outbox.lock.Unlock()
+		log.Infow("交易已从outbox写入数据库", "tx_id", entry.TxID, "org", entry.OrgName, "channel", entry.ChannelID, "attempts", entry.Attempts)
+	}
+}
+
+func (outbox *Outbox) retry(entry *OutboxEntry) error {
+	ctx := context.Background()
+	exists, err := models.TxHistoryExists(ctx, entry.TxID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	if entry.TxHistory == nil {
+		setup, err := outboxSetup(entry)
+		if err != nil {
+			return err
+		}
+		if err = setup.fillOutboxEntry(entry); err != nil {
+			return err
+		}
+	}
+	return models.CreateTxRecords(ctx, entry.BlockTXInfo, entry.TxHistory)
+}
+
+// 根据记录中的组织和用户获取账本客户端
+func outboxSetup(entry *OutboxEntry) (*Setup, error) {
+	setup, err := NewLedgerSetup(entry.ChannelID, entry.OrgName, entry.UserName)
+	if err != nil {
+		return nil, err
+	}
+	setup.ChaincodeID = entry.ChaincodeID
+	return setup, nil
+}
+
+func (outbox *Outbox) path(txID string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '.' {
+			return '_'
+		}
+		return r
+	}, txID)
+	return filepath.Join(outbox.dir, name+".json")
+}
+
+func readOutboxEntry(file string) (*OutboxEntry, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &OutboxEntry{}
+	err = json.Unmarshal(data, entry)
+	return entry, err
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fabric-client/inits/parse"
+	"fabric-client/models"
+	"fabric-client/tracing"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 单次对账默认的最大区块数
+const defaultReconcileMaxBlocks = 1000
+
+// 对账差异类型
+const (
+	IssueMissing      = "missing"       // 账本中的交易在索引中不存在
+	IssueWrongNumber  = "wrong_number"  // 索引中的区块号与账本不一致
+	IssueHashMismatch = "hash_mismatch" // 索引中的前一区块hash与账本不一致
+	IssueDuplicate    = "duplicate"     // 同一交易在索引中有多条记录
+	IssueStale        = "stale"         // 索引中的交易在账本中不存在或验证未通过
+)
+
+// 对账发现的一条差异，Indexed为索引中的记录，Expected为按账本生成的记录
+type ReconcileIssue struct {
+	Type     string              `json:"type"`
+	TxID     string              `json:"txId"`
+	Indexed  *models.BlockTXInfo `json:"indexed,omitempty"`
+	Expected *models.BlockTXInfo `json:"expected,omitempty"`
+	Repaired bool                `json:"repaired"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// 对账报告
+type ReconcileReport struct {
+	ChannelID     string            `json:"channelId"`
+	StartBlock    uint64            `json:"startBlock"`
+	EndBlock      uint64            `json:"endBlock"`
+	BlocksScanned int               `json:"blocksScanned"`
+	TxScanned     int               `json:"txScanned"`
+	Repair        bool              `json:"repair"`
+	Repaired      int               `json:"repaired"`
+	Issues        []*ReconcileIssue `json:"issues"`
+	StartedAt     int64             `json:"startedAt"`
+	FinishedAt    int64             `json:"finishedAt"`
+}
+
+// ReconcileMaxBlocks 单次对账允许的最大区块数
+func ReconcileMaxBlocks() uint64 {
+	if parse.App.Reconcile.MaxBlocks > 0 {
+		return uint64(parse.App.Reconcile.MaxBlocks)
+	}
+	return defaultReconcileMaxBlocks
+}
+
+// Reconcile 逐个查询账本中[start, end]范围内的区块，与区块交易索引对比并生成报告，
+// end为0时对账到最新区块，repair为true时按账本修复索引
+func (setup *Setup) Reconcile(start uint64, end uint64, repair bool) (report *ReconcileReport, err error) {
+	ctx, span := setup.startSpan("service.reconcile",
+		attribute.Int64("reconcile.start_block", int64(start)), attribute.Int64("reconcile.end_block", int64(end)), attribute.Bool("reconcile.repair", repair))
+	defer func() { tracing.End(span, err) }()
+	setup = setup.withContext(ctx)
+
+	info, err := setup.LClient.QueryInfo()
+	if err != nil {
+		return nil, fmt.Errorf("查询账本高度失败: %v", err)
+	}
+	height := info.BCI.Height
+	if height == 0 {
+		return nil, fmt.Errorf("通道%s的账本为空", setup.ChannelID)
+	}
+	if end == 0 || end >= height {
+		end = height - 1
+	}
+	if start > end {
+		return nil, fmt.Errorf("区块范围错误: %d-%d，当前高度%d", start, end, height)
+	}
+	if end-start+1 > ReconcileMaxBlocks() {
+		return nil, fmt.Errorf("单次对账最多%d个区块", ReconcileMaxBlocks())
+	}
+
+	report = &ReconcileReport{
+		ChannelID:  setup.ChannelID,
+		StartBlock: start,
+		EndBlock:   end,
+		Repair:     repair,
+		Issues:     make([]*ReconcileIssue, 0),
+		StartedAt:  time.Now().Unix(),
+	}
+
+	// 账本中范围内的交易及所在区块号
+	ledgerTxs := make(map[string]uint64)
+	for number := start; number <= end; number++ {
+		block, err := setup.LClient.QueryBlock(number)
+		if err != nil {
+			return nil, fmt.Errorf("查询区块%d失败: %v", number, err)
+		}
+		if err = setup.reconcileBlock(block, ledgerTxs, report); err != nil {
+			return nil, err
+		}
+		report.BlocksScanned++
+	}
+
+	indexed, err := models.GetBlocksByNumberRange(ctx, setup.ChannelID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("查询区块索引失败: %v", err)
+	}
+	for _, row := range indexed {
+		if _, ok := ledgerTxs[row.TxId]; ok {
+			continue
+		}
+		issue, err := setup.outOfRangeIssue(row)
+		if err != nil {
+			return nil, err
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	if repair {
+		for _, issue := range report.Issues {
+			repairIssue(ctx, issue)
+			if issue.Repaired {
+				report.Repaired++
+			}
+		}
+	}
+
+	report.FinishedAt = time.Now().Unix()
+	setup.logger().Infow("对账完成", "start_block", start, "end_block", end,
+		"tx_scanned", report.TxScanned, "issues", len(report.Issues), "repaired", report.Repaired)
+	return report, nil
+}
+
+// 对比一个区块中验证通过的链码交易与索引，索引只保存验证通过的交易
+func (setup *Setup) reconcileBlock(block *common.Block, ledgerTxs map[string]uint64, report *ReconcileReport) error {
+	txs, err := ParseBlockTxs(block)
+	if err != nil {
+		return err
+	}
+
+	expected := make(map[string]*models.BlockTXInfo)
+	txIds := make([]string, 0, len(txs))
+	for _, txMeta := range indexableTxs(txs) {
+		ledgerTxs[txMeta.TxID] = txMeta.BlockNumber
+		expected[txMeta.TxID] = NewBlockTXInfo(txMeta)
+		txIds = append(txIds, txMeta.TxID)
+	}
+	report.TxScanned += len(txIds)
+
+	rows, err := models.GetBlocksByTxIds(setup.context(), setup.ChannelID, txIds)
+	if err != nil {
+		return fmt.Errorf("查询区块索引失败: %v", err)
+	}
+	indexed := make(map[string][]*models.BlockTXInfo)
+	for _, row := range rows {
+		indexed[row.TxId] = append(indexed[row.TxId], row)
+	}
+
+	for _, txID := range txIds {
+		issues := compareIndexed(expected[txID], indexed[txID])
+		report.Issues = append(report.Issues, issues...)
+	}
+	return nil
+}
+
+// 区块中应加入索引的交易，与写入索引时的条件一致
+func indexableTxs(txs []*TxMeta) []*TxMeta {
+	indexable := make([]*TxMeta, 0, len(txs))
+	for _, txMeta := range txs {
+		if txMeta.Type == common.HeaderType_ENDORSER_TRANSACTION && txMeta.ValidationCode == pb.TxValidationCode_VALID {
+			indexable = append(indexable, txMeta)
+		}
+	}
+	return indexable
+}
+
+// 对比账本中的交易和索引中的记录，多条记录时只保留第一条
+func compareIndexed(expected *models.BlockTXInfo, rows []*models.BlockTXInfo) []*ReconcileIssue {
+	if len(rows) == 0 {
+		return []*ReconcileIssue{{Type: IssueMissing, TxID: expected.TxId, Expected: expected}}
+	}
+
+	issues := make([]*ReconcileIssue, 0)
+	row := rows[0]
+	if row.Number != expected.Number {
+		issues = append(issues, &ReconcileIssue{Type: IssueWrongNumber, TxID: expected.TxId, Indexed: row, Expected: expected})
+	} else if row.PreviousHash != expected.PreviousHash {
+		issues = append(issues, &ReconcileIssue{Type: IssueHashMismatch, TxID: expected.TxId, Indexed: row, Expected: expected})
+	}
+	for _, duplicate := range rows[1:] {
+		issues = append(issues, &ReconcileIssue{Type: IssueDuplicate, TxID: expected.TxId, Indexed: duplicate})
+	}
+	return issues
+}
+
+// 索引中区块号在范围内，但对应区块中不存在或验证未通过的交易，在账本其他区块中能查到且验证通过时为区块号错误，
+// 账本中确定不存在或验证未通过时为多余记录。查询失败时返回错误，避免临时故障导致修复时删除索引
+func (setup *Setup) outOfRangeIssue(row *models.BlockTXInfo) (*ReconcileIssue, error) {
+	txMeta, err := setup.QueryTxMeta(fab.TransactionID(row.TxId))
+	if errors.Is(err, ErrTxNotFound) {
+		return &ReconcileIssue{Type: IssueStale, TxID: row.TxId, Indexed: row}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询交易%s失败: %v", row.TxId, err)
+	}
+	if txMeta.ValidationCode != pb.TxValidationCode_VALID {
+		return &ReconcileIssue{Type: IssueStale, TxID: row.TxId, Indexed: row}, nil
+	}
+	return &ReconcileIssue{Type: IssueWrongNumber, TxID: row.TxId, Indexed: row, Expected: NewBlockTXInfo(txMeta)}, nil
+}
+
+// 按账本修复一条差异
+func repairIssue(ctx context.Context, issue *ReconcileIssue) {
+	var err error
+	switch issue.Type {
+	case IssueMissing:
+		_, err = models.CreateBlockInfo(ctx, issue.Expected)
+	case IssueWrongNumber, IssueHashMismatch:
+		issue.Expected.Id = issue.Indexed.Id
+		_, err = models.UpdateBlockInfo(ctx, issue.Expected)
+	case IssueDuplicate, IssueStale:
+		_, err = models.DeleteBlockInfo(ctx, issue.Indexed.Id)
+	}
+
+	if err != nil {
+		issue.Error = err.Error()
+		log.Errorw("修复交易索引失败", "tx_id", issue.TxID, "issue", issue.Type, "error", err)
+		return
+	}
+	issue.Repaired = true
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+var (
+	stopping   = make(chan struct{})
+	stopOnce   sync.Once
+	background sync.WaitGroup
+)
+
+// Go 启动后台任务，Shutdown时等待其完成。长时间等待的任务需要监听Stopping
+func Go(task func()) {
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		task()
+	}()
+}
+
+// Stopping 服务停止时关闭，后台任务据此退出
+func Stopping() <-chan struct{} {
+	return stopping
+}
+
+// Shutdown 通知后台任务退出，并等待进行中的事件回调、异步交易和定时任务完成，
+// ctx到期时不再等待并返回错误
+func Shutdown(ctx context.Context) error {
+	stopOnce.Do(func() { close(stopping) })
+
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("等待后台任务完成超时: %v", ctx.Err())
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"bytes"
+	"fabric-client/metrics"
+	"fabric-client/tracing"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 模拟执行的结果，交易不会发送到排序节点
+type SimulationResult struct {
+	TxID         string
+	Consistent   bool // 所有背书节点的返回结果是否一致
+	Endorsements []*EndorsementResult
+}
+
+// 单个背书节点的背书结果
+type EndorsementResult struct {
+	Endorser        string
+	Status          int32
+	ChaincodeStatus int32
+	Message         string
+	Payload         []byte
+	ReadWriteSets   []*NsReadWriteSet
+}
+
+// 链码命名空间的读写集
+type NsReadWriteSet struct {
+	Namespace string
+	Reads     []*KVRead
+	Writes    []*KVWrite
+}
+
+type KVRead struct {
+	Key      string
+	BlockNum uint64 // 读取的版本，键不存在时为0
+	TxNum    uint64
+}
+
+type KVWrite struct {
+	Key      string
+	IsDelete bool
+	Value    []byte
+}
+
+// Simulate 收集背书并返回各节点的背书结果和读写集，不发送交易到排序节点
+func (setup *Setup) Simulate(fcn string, args [][]byte) (result *SimulationResult, err error) {
+	_, span := setup.startSpan("service.simulate", attribute.String("fabric.fcn", fcn))
+	defer func() { tracing.End(span, err) }()
+
+	request := channel.Request{
+		ChaincodeID: setup.ChaincodeID,
+		Fcn:         fcn,
+		Args:        args,
+	}
+
+	// 不使用背书一致性校验，由结果中的Consistent字段返回
+	start := time.Now()
+	response, err := setup.Client.InvokeHandler(
+		invoke.NewProposalProcessorHandler(
+			invoke.NewEndorsementHandler(
+				invoke.NewSignatureValidationHandler(),
+			),
+		),
+		request,
+		setup.Options...,
+	)
+	metrics.ObserveSDK(setup.OrgName, "simulate", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &SimulationResult{
+		TxID:         string(response.TransactionID),
+		Consistent:   len(response.Responses) > 0,
+		Endorsements: make([]*EndorsementResult, 0, len(response.Responses)),
+	}
+	for _, proposalResponse := range response.Responses {
+		endorsement, err := parseEndorsement(proposalResponse)
+		if err != nil {
+			return nil, err
+		}
+		result.Endorsements = append(result.Endorsements, endorsement)
+
+		if proposalResponse.Status != 200 || !bytes.Equal(proposalResponse.ProposalResponse.GetPayload(), response.Responses[0].ProposalResponse.GetPayload()) {
+			result.Consistent = false
+		}
+	}
+	return result, nil
+}
+
+// 解析背书节点返回的链码执行结果和读写集
+func parseEndorsement(proposalResponse *fab.TransactionProposalResponse) (*EndorsementResult, error) {
+	endorsement := &EndorsementResult{
+		Endorser:        proposalResponse.Endorser,
+		Status:          proposalResponse.Status,
+		ChaincodeStatus: proposalResponse.ChaincodeStatus,
+		Message:         proposalResponse.ProposalResponse.GetResponse().GetMessage(),
+	}
+
+	responsePayload := &pb.ProposalResponsePayload{}
+	if err := proto.Unmarshal(proposalResponse.ProposalResponse.GetPayload(), responsePayload); err != nil {
+		return nil, fmt.Errorf("解析节点【%s】的背书结果失败: %v", proposalResponse.Endorser, err)
+	}
+
+	chaincodeAction := &pb.ChaincodeAction{}
+	if err := proto.Unmarshal(responsePayload.Extension, chaincodeAction); err != nil {
+		return nil, fmt.Errorf("解析节点【%s】的链码执行结果失败: %v", proposalResponse.Endorser, err)
+	}
+	endorsement.Payload = chaincodeAction.GetResponse().GetPayload()
+
+	txRWSet := &rwset.TxReadWriteSet{}
+	if err := proto.Unmarshal(chaincodeAction.Results, txRWSet); err != nil {
+		return nil, fmt.Errorf("解析节点【%s】的读写集失败: %v", proposalResponse.Endorser, err)
+	}
+
+	for _, nsRWSet := range txRWSet.NsRwset {
+		kvRWSet := &kvrwset.KVRWSet{}
+		if err := proto.Unmarshal(nsRWSet.Rwset, kvRWSet); err != nil {
+			return nil, fmt.Errorf("解析节点【%s】的%s读写集失败: %v", proposalResponse.Endorser, nsRWSet.Namespace, err)
+		}
+
+		readWriteSet := &NsReadWriteSet{Namespace: nsRWSet.Namespace}
+		for _, read := range kvRWSet.Reads {
+			readWriteSet.Reads = append(readWriteSet.Reads, &KVRead{
+				Key:      read.Key,
+				BlockNum: read.GetVersion().GetBlockNum(),
+				TxNum:    read.GetVersion().GetTxNum(),
+			})
+		}
+		for _, write := range kvRWSet.Writes {
+			readWriteSet.Writes = append(readWriteSet.Writes, &KVWrite{
+				Key:      write.Key,
+				IsDelete: write.IsDelete,
+				Value:    write.Value,
+			})
+		}
+		endorsement.ReadWriteSets = append(endorsement.ReadWriteSets, readWriteSet)
+	}
+	return endorsement, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fabric-client/metrics"
+	"fabric-client/tracing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 链码调用各阶段的计时和span，每次调用创建新的实例，插入到invoke处理链中
+type invokeTimer struct {
+	ctx          context.Context
+	channelID    string
+	chaincodeID  string
+	endorseStart time.Time
+	commitStart  time.Time
+}
+
+func (setup *Setup) newInvokeTimer(ctx context.Context) *invokeTimer {
+	return &invokeTimer{ctx: ctx, channelID: setup.ChannelID, chaincodeID: setup.ChaincodeID}
+}
+
+// 函数形式的invoke.Handler
+type handlerFunc func(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext)
+
+func (handler handlerFunc) Handle(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+	handler(requestContext, clientContext)
+}
+
+// beforeEndorsement 在收集背书前开始计时，next为背书处理
+func (timer *invokeTimer) beforeEndorsement(next invoke.Handler) invoke.Handler {
+	return handlerFunc(func(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+		timer.endorseStart = time.Now()
+		next.Handle(requestContext, clientContext)
+	})
+}
+
+// afterEndorsement 背书校验通过后记录背书时长并开始提交计时，next为提交处理
+func (timer *invokeTimer) afterEndorsement(next invoke.Handler) invoke.Handler {
+	return handlerFunc(func(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+		metrics.EndorsementDuration.WithLabelValues(timer.channelID, timer.chaincodeID).Observe(time.Since(timer.endorseStart).Seconds())
+		tracing.Record(timer.ctx, "chaincode.endorsement", timer.endorseStart, nil,
+			attribute.Int("fabric.endorsements", len(requestContext.Response.Responses)))
+		timer.commitStart = time.Now()
+		next.Handle(requestContext, clientContext)
+	})
+}
+
+// commit 同步提交完成后记录提交时长，交易无效时requestContext.Error不为空
+func (timer *invokeTimer) commit(next invoke.Handler) invoke.Handler {
+	return handlerFunc(func(requestContext *invoke.RequestContext, clientContext *invoke.ClientContext) {
+		next.Handle(requestContext, clientContext)
+		timer.observeCommit(requestContext.Error)
+	})
+}
+
+// observeCommit 记录从发送交易到接收到提交事件的时长
+func (timer *invokeTimer) observeCommit(err error) {
+	metrics.CommitDuration.WithLabelValues(timer.channelID, timer.chaincodeID, metrics.Status(err)).Observe(time.Since(timer.commitStart).Seconds())
+	tracing.Record(timer.ctx, "chaincode.commit", timer.commitStart, err)
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"fabric-client/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 发起调用的context，未设置时使用Background
+func (setup *Setup) context() context.Context {
+	if setup.Context == nil {
+		return context.Background()
+	}
+	return setup.Context
+}
+
+// 返回使用ctx的Setup副本，使嵌套调用的span挂在当前span下
+func (setup *Setup) withContext(ctx context.Context) *Setup {
+	child := *setup
+	child.Context = ctx
+	return &child
+}
+
+// 开始Setup方法的span，附加组织、用户、通道和链码
+func (setup *Setup) startSpan(name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		attribute.String("fabric.org", setup.OrgName),
+		attribute.String("fabric.user", setup.UserName),
+		attribute.String("fabric.channel", setup.ChannelID),
+		attribute.String("fabric.chaincode", setup.ChaincodeID),
+	)
+	return tracing.Start(setup.context(), name, attrs...)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fabric-client/inits/parse"
+	"sync"
+	"time"
+)
+
+// 交易状态
+const (
+	TxPending   = "pending"   //已提交，等待出块
+	TxCommitted = "committed" //已提交且验证通过
+	TxInvalid   = "invalid"   //已出块但验证失败
+	TxUnknown   = "unknown"   //等待提交事件超时或服务停止，且账本中查不到交易，交易仍可能上链
+)
+
+// 异步交易的状态
+type TxState struct {
+	TxID           string
+	ChannelID      string
+	ChaincodeID    string
+	Status         string
+	ValidationCode string
+	BlockNumber    uint64
+	Payload        []byte
+	Error          string `json:",omitempty"`
+	SubmittedAt    int64
+	UpdatedAt      int64
+}
+
+// 根据提交事件跟踪异步交易的状态
+type TxTracker struct {
+	lock   sync.RWMutex
+	states map[string]*TxState
+}
+
+var Tracker = &TxTracker{states: make(map[string]*TxState)}
+
+// Track 开始跟踪一笔交易
+func (tracker *TxTracker) Track(state *TxState) {
+	now := time.Now().Unix()
+	state.SubmittedAt = now
+	state.UpdatedAt = now
+
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+	tracker.states[state.TxID] = state
+}
+
+// Get 获取交易状态的副本
+func (tracker *TxTracker) Get(txID string) (TxState, bool) {
+	tracker.lock.RLock()
+	defer tracker.lock.RUnlock()
+	state, ok := tracker.states[txID]
+	if !ok {
+		return TxState{}, false
+	}
+	return *state, true
+}
+
+// Update 更新交易状态
+func (tracker *TxTracker) Update(txID string, update func(state *TxState)) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+	state, ok := tracker.states[txID]
+	if !ok {
+		return
+	}
+	update(state)
+	state.UpdatedAt = time.Now().Unix()
+}
+
+// Prune 删除before之前已结束的交易状态
+func (tracker *TxTracker) Prune(before int64) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+	for txID, state := range tracker.states {
+		if state.Status != TxPending && state.UpdatedAt < before {
+			delete(tracker.states, txID)
+		}
+	}
+}
+
+// StartTxTrackerCleaner 定期清理过期的交易状态
+func StartTxTrackerCleaner() {
+	retention := parse.App.Async.StatusRetention
+	if retention <= 0 {
+		retention = time.Hour
+	}
+
+	Go(func() {
+		ticker := time.NewTicker(retention / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				Tracker.Prune(time.Now().Add(-retention).Unix())
+			case <-stopping:
+				return
+			}
+		}
+	})
+}
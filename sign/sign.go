@@ -0,0 +1,14 @@
+package sign
+
+import (
+	"crypto/md5"
+	"crypto/sha512"
+	"encoding/hex"
+)
+
+// Sign 计算签名原串的签名：先取sha512的十六进制串，再取其md5的十六进制串，服务端和客户端共用
+func Sign(src string) string {
+	sha512Bytes := sha512.Sum512([]byte(src))
+	md5Bytes := md5.Sum([]byte(hex.EncodeToString(sha512Bytes[:])))
+	return hex.EncodeToString(md5Bytes[:])
+}
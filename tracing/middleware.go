@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"fabric-client/logger"
+	"net/http"
+
+	"github.com/kataras/iris/v12"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New 请求链路追踪中间件，从请求头中的traceparent继续链路，并把trace_id附加到请求日志
+func New() iris.Handler {
+	return func(ctx iris.Context) {
+		request := ctx.Request()
+		parent := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+
+		route := request.URL.Path
+		if currentRoute := ctx.GetCurrentRoute(); currentRoute != nil {
+			route = currentRoute.Path()
+		}
+		spanCtx, span := tracer().Start(parent, ctx.Method()+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", ctx.Method()),
+				attribute.String("http.route", route),
+				attribute.String("http.request_id", logger.RequestID(ctx)),
+			))
+		defer span.End()
+
+		ctx.ResetRequest(request.WithContext(spanCtx))
+		if span.SpanContext().IsValid() {
+			logger.AddFields(ctx, "trace_id", span.SpanContext().TraceID().String())
+		}
+
+		ctx.Next()
+
+		status := ctx.GetStatusCode()
+		span.SetAttributes(attribute.Int("http.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// Context 获取请求中带有span的context
+func Context(ctx iris.Context) context.Context {
+	return ctx.Request().Context()
+}
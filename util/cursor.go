@@ -0,0 +1,40 @@
+package util
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// 游标分页结果
+type CursorVO struct {
+	Rows       interface{} `json:"rows"`
+	NextCursor string      `json:"nextCursor"` // 为空时没有下一页
+}
+
+// EncodeCursor 将区块号和id编码为游标
+func EncodeCursor(number uint64, id int) string {
+	src := strconv.FormatUint(number, 10) + ":" + strconv.Itoa(id)
+	return base64.RawURLEncoding.EncodeToString([]byte(src))
+}
+
+// DecodeCursor 从游标中解析区块号和id
+func DecodeCursor(cursor string) (uint64, int, error) {
+	src, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, errors.New("游标格式错误")
+	}
+
+	parts := strings.SplitN(string(src), ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, errors.New("游标格式错误")
+	}
+
+	number, err1 := strconv.ParseUint(parts[0], 10, 64)
+	id, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return 0, 0, errors.New("游标格式错误")
+	}
+	return number, id, nil
+}
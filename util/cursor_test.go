@@ -0,0 +1,50 @@
+package util
+
+import (
+	"encoding/base64"
+	"math"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cases := []struct {
+		number uint64
+		id     int
+	}{
+		{0, 0},
+		{1, 1},
+		{12345, 678},
+		{math.MaxUint64, math.MaxInt32},
+	}
+	for _, c := range cases {
+		cursor := EncodeCursor(c.number, c.id)
+		number, id, err := DecodeCursor(cursor)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%q) error: %v", cursor, err)
+		}
+		if number != c.number || id != c.id {
+			t.Errorf("DecodeCursor(EncodeCursor(%d, %d)) = %d, %d", c.number, c.id, number, id)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	encode := func(src string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(src))
+	}
+	cases := map[string]string{
+		"empty":          "",
+		"not base64":     "!!!",
+		"padded base64":  base64.URLEncoding.EncodeToString([]byte("1:23")),
+		"no separator":   encode("12"),
+		"bad number":     encode("a:2"),
+		"negative block": encode("-1:2"),
+		"bad id":         encode("1:b"),
+		"extra part":     encode("1:2:3"),
+	}
+	for name, cursor := range cases {
+		if _, _, err := DecodeCursor(cursor); err == nil {
+			t.Errorf("%s: DecodeCursor(%q) expected error", name, cursor)
+		}
+	}
+}
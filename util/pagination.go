@@ -2,7 +2,8 @@ package util
 
 import (
 	"errors"
-	"github.com/kataras/iris/v12"
+	"strconv"
+	"time"
 )
 
 // bootstraptable 分页参数
@@ -20,19 +21,39 @@ type Pagination struct {
 	// 时间范围
 	StartDate string
 	EndDate   string
+	StartTime int64 // StartDate解析后的时间戳，0为不限制
+	EndTime   int64 // EndDate解析后的时间戳，0为不限制
 
-	Uid int64 // 公用的特殊参数
-	Sign string
+	// 区块过滤条件，空值或-1为不限制
+	ChannelID  string
+	TxIDPrefix string
+	MinNumber  int64
+	MaxNumber  int64
+
+	Cursor string // 游标分页的起始位置，为空时从头开始
+
+	// 时间的输出格式，Location为Timezone解析后的时区，同时用于解析日期参数
+	Timezone   string
+	TimeFormat string
+	Location   *time.Location
+
+	Sign      string
 	Timestamp int64
 }
 
-func NewPagination(ctx iris.Context) (*Pagination, error) {
-	pageNumber, err1 := ctx.URLParamInt("pageNumber")
-	pageSize, err2 := ctx.URLParamInt("pageSize")
-	timestamp, err3 := ctx.URLParamInt64("timestamp")
-	sortName := ctx.URLParam("sortName")
-	sortOrder := ctx.URLParam("sortOrder")
-	sign := ctx.URLParam("sign")
+// 只有日期的格式，作为结束日期时包含当天
+const dateOnlyLayout = "2006-01-02"
+
+// 日期参数支持的格式，也可以直接传时间戳
+var dateLayouts = []string{"2006-01-02 15:04:05", dateOnlyLayout, time.RFC3339}
+
+func NewPagination(params Params) (*Pagination, error) {
+	pageNumber, err1 := params.URLParamInt("pageNumber")
+	pageSize, err2 := params.URLParamInt("pageSize")
+	timestamp, err3 := params.URLParamInt64("timestamp")
+	sortName := params.URLParam("sortName")
+	sortOrder := params.URLParam("sortOrder")
+	sign := params.URLParam("sign")
 	if err1 != nil || err2 != nil || err3 != nil {
 		return nil, errors.New("请求的分页参数解析错误.")
 	}
@@ -42,13 +63,84 @@ func NewPagination(ctx iris.Context) (*Pagination, error) {
 		PageSize:   pageSize,
 		SortName:   sortName,
 		SortOrder:  sortOrder,
-		Sign:  sign,
-		Timestamp: timestamp,
+		Sign:       sign,
+		Timestamp:  timestamp,
+	}
+	if err := page.parseFilters(params); err != nil {
+		return nil, err
+	}
+
+	page.pageSetting()
+	return &page, nil
+}
+
+// NewCursorPagination 解析游标分页参数，每页条数使用pageSize
+func NewCursorPagination(params Params) (*Pagination, error) {
+	pageSize, err1 := params.URLParamInt("pageSize")
+	timestamp, err2 := params.URLParamInt64("timestamp")
+	if err1 != nil || err2 != nil {
+		return nil, errors.New("请求的分页参数解析错误.")
+	}
+
+	page := Pagination{
+		PageNumber: 1,
+		PageSize:   pageSize,
+		SortOrder:  params.URLParamDefault("sortOrder", "asc"),
+		Cursor:     params.URLParam("cursor"),
+		Sign:       params.URLParam("sign"),
+		Timestamp:  timestamp,
+	}
+	if err := page.parseFilters(params); err != nil {
+		return nil, err
 	}
+
 	page.pageSetting()
 	return &page, nil
 }
 
+// 解析过滤条件参数
+func (p *Pagination) parseFilters(params Params) error {
+	p.StartDate = params.URLParam("startDate")
+	p.EndDate = params.URLParam("endDate")
+	p.ChannelID = params.URLParam("channelID")
+	p.TxIDPrefix = params.URLParam("txIDPrefix")
+	p.MinNumber = params.URLParamInt64Default("minNumber", -1)
+	p.MaxNumber = params.URLParamInt64Default("maxNumber", -1)
+	if err := p.parseTimeParams(params); err != nil {
+		return err
+	}
+
+	var err error
+	if p.StartTime, err = parseDate(p.StartDate, p.Location, false); err != nil {
+		return errors.New("请求的开始日期解析错误.")
+	}
+	if p.EndTime, err = parseDate(p.EndDate, p.Location, true); err != nil {
+		return errors.New("请求的结束日期解析错误.")
+	}
+	return nil
+}
+
+// FilterSignSource 过滤条件的签名原串，未设置的条件不参与签名
+func (p *Pagination) FilterSignSource() string {
+	src := ""
+	if p.EndDate != "" {
+		src += "&EndDate=" + p.EndDate
+	}
+	if p.ChannelID != "" {
+		src += "&ChannelID=" + p.ChannelID
+	}
+	if p.TxIDPrefix != "" {
+		src += "&TxIDPrefix=" + p.TxIDPrefix
+	}
+	if p.MinNumber >= 0 {
+		src += "&MinNumber=" + strconv.FormatInt(p.MinNumber, 10)
+	}
+	if p.MaxNumber >= 0 {
+		src += "&MaxNumber=" + strconv.FormatInt(p.MaxNumber, 10)
+	}
+	return src + p.TimeSignSource()
+}
+
 // 设置分页参数
 func (p *Pagination) pageSetting() {
 	if p.PageNumber < 1 {
@@ -61,3 +153,25 @@ func (p *Pagination) pageSetting() {
 	p.Start = (p.PageNumber - 1) * p.PageSize
 	p.Limit = p.PageSize
 }
+
+// 解析日期参数，不带时区的日期按location解析，为空时返回0。
+// 时间范围两端都包含，endOfDay为true时只有日期的参数解析为当天的最后一秒，结束日期包含当天
+func parseDate(date string, location *time.Location, endOfDay bool) (int64, error) {
+	if date == "" {
+		return 0, nil
+	}
+
+	if timestamp, err := strconv.ParseInt(date, 10, 64); err == nil {
+		return timestamp, nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, date, location); err == nil {
+			if endOfDay && layout == dateOnlyLayout {
+				t = t.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			return t.Unix(), nil
+		}
+	}
+	return 0, errors.New("日期格式错误")
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNewPaginationDateRange(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name      string
+		startDate string
+		endDate   string
+		startTime int64
+		endTime   int64
+	}{
+		{"date only", "2020-09-13", "2020-09-13",
+			time.Date(2020, 9, 13, 0, 0, 0, 0, shanghai).Unix(), time.Date(2020, 9, 13, 23, 59, 59, 0, shanghai).Unix()},
+		{"date and time", "2020-09-13 08:00:00", "2020-09-13 18:30:00",
+			time.Date(2020, 9, 13, 8, 0, 0, 0, shanghai).Unix(), time.Date(2020, 9, 13, 18, 30, 0, 0, shanghai).Unix()},
+		{"rfc3339", "2020-09-13T00:00:00Z", "2020-09-14T00:00:00Z",
+			time.Date(2020, 9, 13, 0, 0, 0, 0, time.UTC).Unix(), time.Date(2020, 9, 14, 0, 0, 0, 0, time.UTC).Unix()},
+		{"timestamp", "1600000000", "1600086400", 1600000000, 1600086400},
+		{"unbounded", "", "", 0, 0},
+	}
+	for _, c := range cases {
+		params := QueryParams(url.Values{"pageNumber": {"1"}, "pageSize": {"10"}, "timestamp": {"1"}, "tz": {"Asia/Shanghai"},
+			"startDate": {c.startDate}, "endDate": {c.endDate}})
+		page, err := NewPagination(params)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if page.StartTime != c.startTime || page.EndTime != c.endTime {
+			t.Errorf("%s: 时间范围为[%d, %d]，应为[%d, %d]", c.name, page.StartTime, page.EndTime, c.startTime, c.endTime)
+		}
+	}
+
+	params := QueryParams(url.Values{"pageNumber": {"1"}, "pageSize": {"10"}, "timestamp": {"1"}, "endDate": {"2020/09/13"}})
+	if _, err := NewPagination(params); err == nil {
+		t.Error("不支持的日期格式应返回错误")
+	}
+}
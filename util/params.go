@@ -0,0 +1,58 @@
+package util
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// 分页和过滤参数的来源，iris.Context实现了该接口，其他调用方使用QueryParams
+type Params interface {
+	URLParam(name string) string
+	URLParamDefault(name string, def string) string
+	URLParamInt(name string) (int, error)
+	URLParamInt64(name string) (int64, error)
+	URLParamInt64Default(name string, def int64) int64
+}
+
+// QueryParams 按与iris.Context相同的规则读取参数，用于gRPC等非HTTP请求
+type QueryParams url.Values
+
+func (params QueryParams) URLParam(name string) string {
+	return url.Values(params).Get(name)
+}
+
+// URLParamDefault 参数为空时返回def
+func (params QueryParams) URLParamDefault(name string, def string) string {
+	if value := params.URLParam(name); value != "" {
+		return value
+	}
+	return def
+}
+
+// URLParamInt 参数为空或不是整数时返回错误
+func (params QueryParams) URLParamInt(name string) (int, error) {
+	value := params.URLParam(name)
+	if value == "" {
+		return -1, errors.New("参数" + name + "不存在")
+	}
+	return strconv.Atoi(value)
+}
+
+// URLParamInt64 参数为空或不是整数时返回错误
+func (params QueryParams) URLParamInt64(name string) (int64, error) {
+	value := params.URLParam(name)
+	if value == "" {
+		return -1, errors.New("参数" + name + "不存在")
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// URLParamInt64Default 参数为空或不是整数时返回def
+func (params QueryParams) URLParamInt64Default(name string, def int64) int64 {
+	value, err := params.URLParamInt64(name)
+	if err != nil {
+		return def
+	}
+	return value
+}
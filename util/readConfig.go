@@ -1,18 +1,20 @@
 package util
 
 import (
-	"github.com/kataras/golog"
+	"fabric-client/logger"
 	"gopkg.in/yaml.v2"
 	"os"
 )
 
+var log = logger.Named("util")
+
 func ReadYamlConfig(path string,config interface{}) error {
 	f,err:=os.Open(path)
 	defer f.Close()
 	if err!=nil{
-		golog.Fatalf("打开文件失败:%s",err)
+		log.Fatalf("打开文件失败:%s",err)
 	}
 
 	yaml.NewDecoder(f).Decode(config)
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"errors"
+	"fabric-client/inits/parse"
+	"time"
+)
+
+// 时间戳的输出格式
+const (
+	TimeFormatUnix    = "unix"    // 只返回timestamp、timestamp_ns字段，默认
+	TimeFormatRFC3339 = "rfc3339" // 额外返回RFC 3339格式的time字段，保留纳秒精度
+)
+
+// parseTimeParams 解析时区和时间格式参数，时区为空时使用db.yaml中配置的时区
+func (p *Pagination) parseTimeParams(params Params) error {
+	p.Timezone = params.URLParam("tz")
+	p.TimeFormat = params.URLParamDefault("timeFormat", TimeFormatUnix)
+	if p.TimeFormat != TimeFormatUnix && p.TimeFormat != TimeFormatRFC3339 {
+		return errors.New("不支持的时间格式: " + p.TimeFormat)
+	}
+
+	p.Location = parse.DB.Location()
+	if p.Timezone != "" {
+		location, err := time.LoadLocation(p.Timezone)
+		if err != nil {
+			return errors.New("时区参数解析错误: " + p.Timezone)
+		}
+		p.Location = location
+	}
+	return nil
+}
+
+// TimeSignSource 时区和时间格式的签名原串，未设置时不参与签名
+func (p *Pagination) TimeSignSource() string {
+	src := ""
+	if p.Timezone != "" {
+		src += "&Timezone=" + p.Timezone
+	}
+	if p.TimeFormat != TimeFormatUnix {
+		src += "&TimeFormat=" + p.TimeFormat
+	}
+	return src
+}
+
+// FormatTime 按请求的时区和格式渲染UTC纳秒时间戳，unix格式时返回空串
+func (p *Pagination) FormatTime(timestampNs int64) string {
+	if p.TimeFormat != TimeFormatRFC3339 || timestampNs == 0 {
+		return ""
+	}
+	return time.Unix(0, timestampNs).In(p.Location).Format(time.RFC3339Nano)
+}
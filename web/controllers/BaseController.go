@@ -1,80 +1,115 @@
 package controllers
 
 import (
-	"crypto/md5"
-	"crypto/sha512"
-	"encoding/hex"
+	"context"
+	"fabric-client/sign"
+	"fabric-client/tracing"
 	"time"
 
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/middleware/i18n"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
 )
 
 type Result struct {
 	Code    int
 	Message string
 	Data    interface{}
+	Warning string `json:",omitempty"` // 请求成功但有需要注意的情况，如交易已上链但索引延后
+	Error   string `json:",omitempty"` // 错误码对应的字符串标识，不随提示语言变化，见错误目录
 }
 
 const (
-	OK                     = 0  //成功
-	ParseParamsError       = 1  //解析参数错误
-	SignExpiredError       = 2  //签名过期
-	SignInvalidError       = 3  //签名错误
-	GetAndCheckClientError = 4  //获取并检查客户端错误
-	CreateChannelError     = 5  //创建通道失败
-	JoinChannelError       = 6  //加入通道失败
-	InstallCCError         = 7  //安装链码失败
-	InstantiateCCError     = 8  //初始化失败
-	UpgradeCCError         = 9  //更新失败
-	ArgsError              = 10 //参数或者参数长度错误
-	NewChannelClientError  = 11 //新建通道客户端错误
-	ExecCCError            = 12 //执行失败
-	QueryCCError           = 13 //查询失败
-	NewLedgerClientError   = 14 //新建账本客户端错误
-	QueryBlockError        = 15 //查询block失败
-	QueryBlockByIdError    = 16 //根据txid查询block失败
+	OK                       = 0  //成功
+	ParseParamsError         = 1  //解析参数错误
+	SignExpiredError         = 2  //签名过期
+	SignInvalidError         = 3  //签名错误
+	GetAndCheckClientError   = 4  //获取并检查客户端错误
+	CreateChannelError       = 5  //创建通道失败
+	JoinChannelError         = 6  //加入通道失败
+	InstallCCError           = 7  //安装链码失败
+	InstantiateCCError       = 8  //初始化失败
+	UpgradeCCError           = 9  //更新失败
+	ArgsError                = 10 //参数或者参数长度错误
+	NewChannelClientError    = 11 //新建通道客户端错误
+	ExecCCError              = 12 //执行失败
+	QueryCCError             = 13 //查询失败
+	NewLedgerClientError     = 14 //新建账本客户端错误
+	QueryBlockError          = 15 //查询block失败
+	QueryBlockByIdError      = 16 //根据txid查询block失败
+	IdempotencyConflictError = 17 //相同幂等请求正在处理
+	IdempotencyRecordError   = 18 //幂等记录读写失败
+	TxNotFoundError          = 19 //交易不存在
+	BatchPartialError        = 20 //批量执行部分失败
+	RequestOptionsError      = 21 //请求选项错误
+	SimulateCCError          = 22 //模拟执行失败
+	EndorsementMismatchError = 23 //背书结果不一致
+	QueryTxHistoryError      = 24 //查询交易历史失败
+	SortParamsError          = 25 //排序参数错误
+	ReconcileError           = 26 //账本对账失败
+	NotReadyError            = 27 //服务未就绪
+	ClientUnavailableError   = 28 //组织客户端初始化失败，暂不可用
+	RateLimitedError         = 29 //请求超过限流配额
+	MVCCConflictError        = 30 //读写集冲突，交易验证失败
+	SDKTimeoutError          = 31 //背书、排序或提交超时
+	PolicyFailureError       = 32 //不满足背书策略或没有访问权限
+	NewEventClientError      = 33 //新建事件客户端错误
+	SubscribeEventsError     = 34 //订阅事件失败
+	IdempotencyMismatchError = 35 //幂等请求ID已用于内容不同的请求
 )
 
 func parseJson(ctx iris.Context, jsonObjectPtr interface{}) Result {
 	err := ctx.ReadJSON(jsonObjectPtr)
 	if err != nil {
-		return getBadRequestResult(ctx, ParseParamsError, i18n.Translate(ctx, "parse_params_fail"), err.Error())
+		return getErrorResult(ctx, ParseParamsError, i18n.Translate(ctx, "parse_params_fail"), err.Error())
 	}
 
 	return Result{Code: OK}
 }
 
-func checkSign(ctx iris.Context, timestamp int64, sign string, src string) Result {
+// 请求的上下文，REST请求和gRPC调用分别提供trace context、提示语言和请求日志
+type requestScope interface {
+	context() context.Context
+	translate(format string, args ...interface{}) string
+	requestLogger() *zap.SugaredLogger
+}
+
+// 校验签名，返回结果不设置HTTP状态码
+func verifySign(scope requestScope, timestamp int64, sign string, src string) Result {
+	_, span := tracing.Start(scope.context(), "check_sign")
+	defer span.End()
+
 	currentTimestamp := time.Now().Unix()
-	ctx.Application().Logger().Println(currentTimestamp)
 	if currentTimestamp-timestamp > 120 {
-		return getInternalServerError(ctx, SignExpiredError, i18n.Translate(ctx, "sign_expired"), nil)
+		scope.requestLogger().Warnw("签名已过期", "timestamp", timestamp, "now", currentTimestamp)
+		span.SetAttributes(attribute.String("sign.result", "expired"))
+		return newErrorResult(SignExpiredError, scope.translate("sign_expired"), nil)
 	}
 
-	currentSign := getSign(src)
-	ctx.Application().Logger().Println(currentSign + ":" + sign)
-	if currentSign != sign {
-		return getInternalServerError(ctx, SignInvalidError, i18n.Translate(ctx, "sign_invalid"), src+":"+currentSign+":"+sign)
+	// 不记录和返回正确的签名，只返回签名原串便于调用方核对
+	if getSign(src) != sign {
+		scope.requestLogger().Warnw("签名校验失败", "timestamp", timestamp)
+		span.SetAttributes(attribute.String("sign.result", "invalid"))
+		return newErrorResult(SignInvalidError, scope.translate("sign_invalid"), src)
 	}
 
+	span.SetAttributes(attribute.String("sign.result", "ok"))
 	return Result{Code: OK}
 }
 
 func getSign(src string) string {
-	sha512Bytes := sha512.Sum512([]byte(src))
-	sha512 := hex.EncodeToString(sha512Bytes[:])
-	md5Bytes := md5.Sum([]byte(sha512))
-	sign := hex.EncodeToString(md5Bytes[:])
-	return sign
+	return sign.Sign(src)
 }
 
-func getBadRequestResult(ctx iris.Context, code int, message string, data interface{}) Result {
-	ctx.StatusCode(iris.StatusBadRequest)
-	return Result{Code: code, Message: message, Data: data}
+// 错误结果，按错误目录设置HTTP状态码
+func getErrorResult(ctx iris.Context, code int, message string, data interface{}) Result {
+	result := newErrorResult(code, message, data)
+	setErrorStatus(ctx, result)
+	return result
 }
 
-func getInternalServerError(ctx iris.Context, code int, message string, data interface{}) Result {
-	ctx.StatusCode(iris.StatusInternalServerError)
-	return Result{Code: code, Message: message, Data: data}
+// 不设置HTTP状态码的错误结果，用于并发执行
+func newErrorResult(code int, message string, data interface{}) Result {
+	return Result{Code: code, Message: message, Data: data, Error: errorID(code)}
 }
@@ -2,26 +2,51 @@ package controllers
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"fabric-client/inits/parse"
+	"fabric-client/logger"
+	"fabric-client/metrics"
 	"fabric-client/models"
 	"fabric-client/sdkInit"
 	"fabric-client/service"
+	"fabric-client/tracing"
 	"fabric-client/util"
 	"fmt"
 	"github.com/kataras/iris/v12"
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/golang/protobuf/ptypes/timestamp"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contribi18n "github.com/iris-contrib/i18n"
 	"github.com/kataras/iris/v12/middleware/i18n"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
+var log = logger.Named("web")
+
+// 幂等请求ID的请求头
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// 正在处理中的幂等请求
+var pendingRequests sync.Map
+
 type FabricSDKController struct {
 	Ctx       iris.Context
 	ClientMap map[string]*sdkInit.Client
+
+	rpc *rpcCall // gRPC调用时不为nil，此时Ctx为nil
 }
 
 type ChannelRequest struct {
@@ -39,12 +64,56 @@ type ChaincodeRequest struct {
 	ChaincodeID      string
 	Fcn              string
 	Args             []string
-	EventFilter      string //查询链码不用传
-	EventCallbackUrl string //查询链码不用传
+	EventFilter      string   //查询链码不用传
+	EventCallbackUrl string   //查询链码不用传
+	RequestID        string   //幂等请求ID，也可通过Idempotency-Key请求头传入，两者都参与签名，查询链码不用传
+	Async            bool     //异步执行，提交后立即返回交易ID，查询链码不用传
+	DryRun           bool     //只模拟执行并返回背书结果，不发送到排序节点，查询链码不用传
+	TargetPeers      []string //指定背书节点，节点名称或URL，必须属于该组织
+	Timeout          int64    //请求超时时间（毫秒），0为SDK默认值
+	RetryAttempts    int      //失败重试次数，0为不重试
+	RetryBackoff     int64    //首次重试等待时间（毫秒），0为SDK默认值
 	Timestamp        int64
 	Sign             string
 }
 
+type BatchChaincodeRequest struct {
+	Requests  []*ChaincodeRequest //批量执行的链码请求，各项不需要Timestamp和Sign
+	Timestamp int64
+	Sign      string
+}
+
+type BatchItemResult struct {
+	Index int //请求在批量请求中的序号
+	Result
+}
+
+type BatchResult struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	Items     []BatchItemResult
+}
+
+// 账本对账请求，EndBlock为0时对账到最新区块，Repair为true时按账本修复索引
+type ReconcileRequest struct {
+	ChannelID  string
+	OrgName    string
+	UserName   string
+	StartBlock uint64
+	EndBlock   uint64
+	Repair     bool
+	Timestamp  int64
+	Sign       string
+}
+
+// 批量执行的默认并发数和最大请求数
+const (
+	defaultBatchParallelism = 8
+	defaultBatchMaxItems    = 1000
+	defaultBatchMaxBody     = 8 << 20 // 批量请求体的默认最大字节数
+)
+
 type BlcockInfo struct {
 	Number       uint64
 	PreviousHash string
@@ -56,8 +125,13 @@ func (controller *FabricSDKController) PostChannelCreate() Result {
 	if result := controller.parseJson(channelRequest); result.Code != OK {
 		return result
 	}
+	return controller.createChannel(channelRequest)
+}
 
-	src := "orgName=" + channelRequest.OrgName + "&channelID=" + channelRequest.ChannelID + "&timestamp=" + strconv.FormatInt(channelRequest.Timestamp, 10)
+// 创建通道，REST和gRPC共用
+func (controller *FabricSDKController) createChannel(channelRequest *ChannelRequest) Result {
+	controller.addFields("org", channelRequest.OrgName, "channel", channelRequest.ChannelID)
+	src := channelSignSource(channelRequest) + "&timestamp=" + strconv.FormatInt(channelRequest.Timestamp, 10)
 	if result := controller.checkSign(channelRequest.Timestamp, channelRequest.Sign, src); result.Code != OK {
 		return result
 	}
@@ -69,11 +143,11 @@ func (controller *FabricSDKController) PostChannelCreate() Result {
 
 	err := client.CreateChannel(channelRequest.ChannelID)
 	if err != nil {
-		fmt.Println(err.Error())
-		return controller.getInternalServerError(CreateChannelError, i18n.Translate(controller.Ctx, "create_channel_fail"), err.Error())
+		controller.requestLogger().Errorw("创建通道失败", "error", err)
+		return controller.getErrorResult(CreateChannelError, controller.translate("create_channel_fail"), err.Error())
 	}
 
-	return Result{Code: OK, Message: i18n.Translate(controller.Ctx, "create_channel_success")}
+	return Result{Code: OK, Message: controller.translate("create_channel_success")}
 }
 
 // 加入通道
@@ -82,8 +156,13 @@ func (controller *FabricSDKController) PostChaincodeJoin() Result {
 	if result := controller.parseJson(channelRequest); result.Code != OK {
 		return result
 	}
+	return controller.joinChannel(channelRequest)
+}
 
-	src := "orgName=" + channelRequest.OrgName + "&channelID=" + channelRequest.ChannelID + "&timestamp=" + strconv.FormatInt(channelRequest.Timestamp, 10)
+// 加入通道，REST和gRPC共用
+func (controller *FabricSDKController) joinChannel(channelRequest *ChannelRequest) Result {
+	controller.addFields("org", channelRequest.OrgName, "channel", channelRequest.ChannelID)
+	src := channelSignSource(channelRequest) + "&timestamp=" + strconv.FormatInt(channelRequest.Timestamp, 10)
 	if result := controller.checkSign(channelRequest.Timestamp, channelRequest.Sign, src); result.Code != OK {
 		return result
 	}
@@ -95,11 +174,11 @@ func (controller *FabricSDKController) PostChaincodeJoin() Result {
 
 	err := client.JoinChannel(channelRequest.ChannelID)
 	if err != nil {
-		fmt.Println(err.Error())
-		return controller.getInternalServerError(JoinChannelError, i18n.Translate(controller.Ctx, "join_channel_fail"), err.Error())
+		controller.requestLogger().Errorw("加入通道失败", "error", err)
+		return controller.getErrorResult(JoinChannelError, controller.translate("join_channel_fail"), err.Error())
 	}
 
-	return Result{Code: OK, Message: i18n.Translate(controller.Ctx, "join_channel_success")}
+	return Result{Code: OK, Message: controller.translate("join_channel_success")}
 }
 
 // 安装链码
@@ -108,8 +187,13 @@ func (controller *FabricSDKController) PostChaincodeInstall() Result {
 	if result := controller.parseJson(ccRequest); result.Code != OK {
 		return result
 	}
+	return controller.installCC(ccRequest)
+}
 
-	src := "orgName=" + ccRequest.OrgName + "&chaincodeID=" + ccRequest.ChaincodeID + "&chaincodeVersion=" + ccRequest.ChaincodeVersion + "&chaincodePath=" + ccRequest.ChaincodePath + "&timestamp=" + strconv.FormatInt(ccRequest.Timestamp, 10)
+// 安装链码，REST和gRPC共用
+func (controller *FabricSDKController) installCC(ccRequest *sdkInit.CCRequest) Result {
+	controller.addFields("org", ccRequest.OrgName, "chaincode", ccRequest.ChaincodeID)
+	src := installSignSource(ccRequest) + "&timestamp=" + strconv.FormatInt(ccRequest.Timestamp, 10)
 	if result := controller.checkSign(ccRequest.Timestamp, ccRequest.Sign, src); result.Code != OK {
 		return result
 	}
@@ -121,11 +205,11 @@ func (controller *FabricSDKController) PostChaincodeInstall() Result {
 
 	err := client.InstallCC(ccRequest)
 	if err != nil {
-		fmt.Println(err.Error())
-		return controller.getInternalServerError(InstallCCError, i18n.Translate(controller.Ctx, "install_cc_fail"), err.Error())
+		controller.requestLogger().Errorw("安装链码失败", "error", err)
+		return controller.getErrorResult(InstallCCError, controller.translate("install_cc_fail"), err.Error())
 	}
 
-	return Result{Code: OK, Message: i18n.Translate(controller.Ctx, "install_cc_success")}
+	return Result{Code: OK, Message: controller.translate("install_cc_success")}
 }
 
 // 实例化链码
@@ -134,8 +218,13 @@ func (controller *FabricSDKController) PostChaincodeInstantiate() Result {
 	if result := controller.parseJson(ccRequest); result.Code != OK {
 		return result
 	}
+	return controller.instantiateCC(ccRequest)
+}
 
-	src := "channelID=" + ccRequest.ChannelID + "&orgName=" + ccRequest.OrgName + "&chaincodeID=" + ccRequest.ChaincodeID + "&chaincodeVersion=" + ccRequest.ChaincodeVersion + "&chaincodePath=" + ccRequest.ChaincodePath + "&timestamp=" + strconv.FormatInt(ccRequest.Timestamp, 10)
+// 实例化链码，REST和gRPC共用
+func (controller *FabricSDKController) instantiateCC(ccRequest *sdkInit.CCRequest) Result {
+	controller.addFields("org", ccRequest.OrgName, "channel", ccRequest.ChannelID, "chaincode", ccRequest.ChaincodeID)
+	src := ccSignSource(ccRequest) + "&timestamp=" + strconv.FormatInt(ccRequest.Timestamp, 10)
 	if result := controller.checkSign(ccRequest.Timestamp, ccRequest.Sign, src); result.Code != OK {
 		return result
 	}
@@ -147,11 +236,11 @@ func (controller *FabricSDKController) PostChaincodeInstantiate() Result {
 
 	err := client.InstantiateCC(ccRequest)
 	if err != nil {
-		fmt.Println(err.Error())
-		return controller.getInternalServerError(InstantiateCCError, i18n.Translate(controller.Ctx, "instantiate_cc_fail"), err.Error())
+		controller.requestLogger().Errorw("实例化链码失败", "error", err)
+		return controller.getErrorResult(InstantiateCCError, controller.translate("instantiate_cc_fail"), err.Error())
 	}
 
-	return Result{Code: OK, Message: i18n.Translate(controller.Ctx, "instantiate_cc_success")}
+	return Result{Code: OK, Message: controller.translate("instantiate_cc_success")}
 }
 
 // 升级链码
@@ -160,8 +249,13 @@ func (controller *FabricSDKController) PostChaincodeUpgrade() Result {
 	if result := controller.parseJson(ccRequest); result.Code != OK {
 		return result
 	}
+	return controller.upgradeCC(ccRequest)
+}
 
-	src := "channelID=" + ccRequest.ChannelID + "&orgName=" + ccRequest.OrgName + "&chaincodeID=" + ccRequest.ChaincodeID + "&chaincodeVersion=" + ccRequest.ChaincodeVersion + "&chaincodePath=" + ccRequest.ChaincodePath + "&timestamp=" + strconv.FormatInt(ccRequest.Timestamp, 10)
+// 升级链码，REST和gRPC共用
+func (controller *FabricSDKController) upgradeCC(ccRequest *sdkInit.CCRequest) Result {
+	controller.addFields("org", ccRequest.OrgName, "channel", ccRequest.ChannelID, "chaincode", ccRequest.ChaincodeID)
+	src := ccSignSource(ccRequest) + "&timestamp=" + strconv.FormatInt(ccRequest.Timestamp, 10)
 	if result := controller.checkSign(ccRequest.Timestamp, ccRequest.Sign, src); result.Code != OK {
 		return result
 	}
@@ -173,17 +267,11 @@ func (controller *FabricSDKController) PostChaincodeUpgrade() Result {
 
 	err := client.UpgradeCC(ccRequest)
 	if err != nil {
-		fmt.Println(err.Error())
-		return controller.getInternalServerError(UpgradeCCError, i18n.Translate(controller.Ctx, "upgrade_cc_fail"), err.Error())
+		controller.requestLogger().Errorw("升级链码失败", "error", err)
+		return controller.getErrorResult(UpgradeCCError, controller.translate("upgrade_cc_fail"), err.Error())
 	}
 
-	return Result{Code: OK, Message: i18n.Translate(controller.Ctx, "upgrade_cc_success")}
-}
-
-type TxInfo struct {
-	Channel   string
-	TxID      string
-	Timestamp []byte
+	return Result{Code: OK, Message: controller.translate("upgrade_cc_success")}
 }
 
 // 链码执行
@@ -192,97 +280,569 @@ func (controller *FabricSDKController) PostChaincodeExec() Result {
 	if result := controller.parseJson(chaincodeRequest); result.Code != OK {
 		return result
 	}
+	return controller.exec(chaincodeRequest)
+}
 
+// 执行链码，REST和gRPC共用，同步、异步和模拟执行由请求字段决定
+func (controller *FabricSDKController) exec(chaincodeRequest *ChaincodeRequest) Result {
 	if len(chaincodeRequest.Args) < 1 {
-		return controller.getInternalServerError(ArgsError, i18n.Translate(controller.Ctx, "cc_args_len_error", 1), nil)
+		return controller.getErrorResult(ArgsError, controller.translate("cc_args_len_error", 1), nil)
 	}
 
-	src := "args[0]=" + chaincodeRequest.Args[0] + "&channelID=" + chaincodeRequest.ChannelID + "&orgName=" + chaincodeRequest.OrgName + "&userName=" + chaincodeRequest.UserName
-	if len(chaincodeRequest.Args) > 1 {
-		for i := 1; i < len(chaincodeRequest.Args); i++ {
-			src += "&args[" + strconv.Itoa(i) + "]=" + chaincodeRequest.Args[i]
+	// Idempotency-Key请求头等同于请求体中的RequestID，按requestID参与签名，避免被截获的请求更换幂等请求ID
+	if key := controller.idempotencyKey(); key != "" {
+		if chaincodeRequest.RequestID != "" && chaincodeRequest.RequestID != key {
+			return controller.getErrorResult(ArgsError, controller.translate("idempotency_key_mismatch"), key)
 		}
+		chaincodeRequest.RequestID = key
 	}
-	src += "&chaincodeID=" + chaincodeRequest.ChaincodeID + "&fcn=" + chaincodeRequest.Fcn + "&eventCallbackUrl=" + chaincodeRequest.EventCallbackUrl + "&timestamp=" + strconv.FormatInt(chaincodeRequest.Timestamp, 10)
+
+	controller.addChaincodeFields(chaincodeRequest)
+	src := execSignSource(chaincodeRequest) + "&timestamp=" + strconv.FormatInt(chaincodeRequest.Timestamp, 10)
 	if result := controller.checkSign(chaincodeRequest.Timestamp, chaincodeRequest.Sign, src); result.Code != OK {
 		return result
 	}
 
-	serviceSetup, result := controller.getServiceSetup(chaincodeRequest)
+	release, result := controller.acquireOrg(chaincodeRequest.OrgName)
 	if result.Code != OK {
 		return result
 	}
+	defer release()
 
-	if chaincodeRequest.EventFilter != "" {
-		go serviceSetup.SetEvent(chaincodeRequest.EventFilter, chaincodeRequest.EventCallbackUrl, func(eventFilter string, eventCallbackUrl string, event *fab.CCEvent) {
-			if event != nil {
-				fmt.Printf("事件的TxID是%s\n", event.TxID)
-				if eventCallbackUrl != "" {
-					//用http发送event对象到callbackUrl
-					data, err := json.Marshal(event)
-					if err != nil {
-						fmt.Println(err)
-						return
-					}
-
-					resp, err := http.Post(eventCallbackUrl, "application/json", bytes.NewReader(data))
-					defer resp.Body.Close()
-					if err != nil {
-						fmt.Println(err)
-						return
-					}
-
-					body, err := ioutil.ReadAll(resp.Body)
-					if err != nil {
-						fmt.Println(err)
-						return
-					}
-
-					fmt.Println(string(body))
-				}
-			} else {
-				fmt.Printf("不能根据指定的事件ID接收到相应的链码事件(%s)\n", eventFilter)
+	result = controller.execChaincode(controller.context(), chaincodeRequest, chaincodeRequest.RequestID)
+	if txID := resultTxID(result); txID != "" {
+		controller.addFields("tx_id", txID)
+	}
+	if result.Code != OK {
+		controller.setStatus(result)
+	}
+	return result
+}
+
+// 链码执行结果中的交易ID，同步执行返回channel.Response，异步提交返回交易状态，模拟执行和失败时没有交易ID
+func resultTxID(result Result) string {
+	switch data := result.Data.(type) {
+	case channel.Response:
+		return string(data.TransactionID)
+	case service.TxState:
+		return data.TxID
+	}
+	return ""
+}
+
+// 批量链码执行
+func (controller *FabricSDKController) PostChaincodeBatch() Result {
+	// 解析前限制请求体大小，请求数量在解析后才能检查
+	maxBody := parse.App.Batch.MaxBodyBytes
+	if maxBody <= 0 {
+		maxBody = defaultBatchMaxBody
+	}
+	controller.Ctx.SetMaxRequestBodySize(maxBody)
+
+	batchRequest := &BatchChaincodeRequest{}
+	if result := controller.parseJson(batchRequest); result.Code != OK {
+		return result
+	}
+
+	maxItems := parse.App.Batch.MaxItems
+	if maxItems <= 0 {
+		maxItems = defaultBatchMaxItems
+	}
+	if len(batchRequest.Requests) < 1 || len(batchRequest.Requests) > maxItems {
+		return controller.getErrorResult(ArgsError, controller.translate("batch_size_error", maxItems), len(batchRequest.Requests))
+	}
+
+	for i, chaincodeRequest := range batchRequest.Requests {
+		if chaincodeRequest == nil {
+			return controller.getErrorResult(ArgsError, controller.translate("batch_item_empty", i), i)
+		}
+		if len(chaincodeRequest.Args) < 1 {
+			return controller.getErrorResult(ArgsError, controller.translate("cc_args_len_error", 1), i)
+		}
+	}
+
+	src := batchSignSource(batchRequest.Requests) + "&timestamp=" + strconv.FormatInt(batchRequest.Timestamp, 10)
+	if result := controller.checkSign(batchRequest.Timestamp, batchRequest.Sign, src); result.Code != OK {
+		return result
+	}
+
+	parallelism := parse.App.Batch.Parallelism
+	if parallelism <= 0 {
+		parallelism = defaultBatchParallelism
+	}
+
+	ctx := controller.context()
+	items := make([]BatchItemResult, len(batchRequest.Requests))
+	semaphore := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, chaincodeRequest := range batchRequest.Requests {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(index int, chaincodeRequest *ChaincodeRequest) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			// 批量请求中的每一项分别按组织限流，被限流的项单独返回错误
+			release, result := acquireOrg(controller, chaincodeRequest.OrgName)
+			if result.Code != OK {
+				items[index] = BatchItemResult{Index: index, Result: result}
+				return
 			}
+			defer release()
+			items[index] = BatchItemResult{Index: index, Result: controller.execChaincode(ctx, chaincodeRequest, chaincodeRequest.RequestID)}
+		}(i, chaincodeRequest)
+	}
+	wg.Wait()
+
+	batchResult := BatchResult{Total: len(items), Items: items}
+	for _, item := range items {
+		if item.Code == OK {
+			batchResult.Succeeded++
+		} else {
+			batchResult.Failed++
+		}
+	}
+
+	if batchResult.Failed > 0 {
+		return newErrorResult(BatchPartialError, controller.translate("batch_exec_partial_fail", batchResult.Failed), batchResult)
+	}
+	return Result{Code: OK, Message: controller.translate("batch_exec_success"), Data: batchResult}
+}
+
+// 以下为各接口的签名原串，不包含时间戳，与client包中的拼接规则一致，修改时需同时修改client包
+
+// 创建和加入通道
+func channelSignSource(channelRequest *ChannelRequest) string {
+	return "orgName=" + channelRequest.OrgName + "&channelID=" + channelRequest.ChannelID
+}
+
+// 安装链码
+func installSignSource(ccRequest *sdkInit.CCRequest) string {
+	return "orgName=" + ccRequest.OrgName + "&chaincodeID=" + ccRequest.ChaincodeID + "&chaincodeVersion=" + ccRequest.ChaincodeVersion + "&chaincodePath=" + ccRequest.ChaincodePath
+}
+
+// 实例化和升级链码
+func ccSignSource(ccRequest *sdkInit.CCRequest) string {
+	return "channelID=" + ccRequest.ChannelID + "&orgName=" + ccRequest.OrgName + "&chaincodeID=" + ccRequest.ChaincodeID + "&chaincodeVersion=" + ccRequest.ChaincodeVersion + "&chaincodePath=" + ccRequest.ChaincodePath
+}
+
+// 链码请求的参数部分，执行和查询共用
+func argsSignSource(chaincodeRequest *ChaincodeRequest) string {
+	src := "args[0]=" + chaincodeRequest.Args[0] + "&channelID=" + chaincodeRequest.ChannelID + "&orgName=" + chaincodeRequest.OrgName + "&userName=" + chaincodeRequest.UserName
+	for i := 1; i < len(chaincodeRequest.Args); i++ {
+		src += "&args[" + strconv.Itoa(i) + "]=" + chaincodeRequest.Args[i]
+	}
+	return src + "&chaincodeID=" + chaincodeRequest.ChaincodeID + "&fcn=" + chaincodeRequest.Fcn
+}
+
+// 链码查询
+func querySignSource(chaincodeRequest *ChaincodeRequest) string {
+	return argsSignSource(chaincodeRequest) + optionsSignSource(chaincodeRequest)
+}
+
+// 批量执行，各请求的签名原串以|连接
+func batchSignSource(chaincodeRequests []*ChaincodeRequest) string {
+	sources := make([]string, len(chaincodeRequests))
+	for i, chaincodeRequest := range chaincodeRequests {
+		sources[i] = execSignSource(chaincodeRequest)
+	}
+	return strings.Join(sources, "|")
+}
+
+// 链码执行
+func execSignSource(chaincodeRequest *ChaincodeRequest) string {
+	src := argsSignSource(chaincodeRequest) + "&eventCallbackUrl=" + chaincodeRequest.EventCallbackUrl
+	if chaincodeRequest.RequestID != "" {
+		src += "&requestID=" + chaincodeRequest.RequestID
+	}
+	if chaincodeRequest.Async {
+		src += "&async=true"
+	}
+	if chaincodeRequest.DryRun {
+		src += "&dryRun=true"
+	}
+	return src + optionsSignSource(chaincodeRequest)
+}
+
+// 请求选项的签名原串，未设置的选项不参与签名
+func optionsSignSource(chaincodeRequest *ChaincodeRequest) string {
+	src := ""
+	if len(chaincodeRequest.TargetPeers) > 0 {
+		src += "&targetPeers=" + strings.Join(chaincodeRequest.TargetPeers, ",")
+	}
+	if chaincodeRequest.Timeout != 0 {
+		src += "&timeout=" + strconv.FormatInt(chaincodeRequest.Timeout, 10)
+	}
+	if chaincodeRequest.RetryAttempts != 0 {
+		src += "&retryAttempts=" + strconv.Itoa(chaincodeRequest.RetryAttempts)
+	}
+	if chaincodeRequest.RetryBackoff != 0 {
+		src += "&retryBackoff=" + strconv.FormatInt(chaincodeRequest.RetryBackoff, 10)
+	}
+	return src
+}
+
+// 根据请求字段生成SDK请求选项
+func requestOptions(client *sdkInit.Client, chaincodeRequest *ChaincodeRequest, timeoutType fab.TimeoutType) ([]channel.RequestOption, error) {
+	if chaincodeRequest.Timeout < 0 || chaincodeRequest.RetryAttempts < 0 || chaincodeRequest.RetryBackoff < 0 {
+		return nil, fmt.Errorf("请求选项不能为负数")
+	}
+
+	options := make([]channel.RequestOption, 0)
+	if len(chaincodeRequest.TargetPeers) > 0 {
+		if err := client.ValidateTargetPeers(chaincodeRequest.TargetPeers); err != nil {
+			return nil, err
+		}
+		options = append(options, channel.WithTargetEndpoints(chaincodeRequest.TargetPeers...))
+	}
+
+	if chaincodeRequest.Timeout > 0 {
+		options = append(options, channel.WithTimeout(timeoutType, time.Duration(chaincodeRequest.Timeout)*time.Millisecond))
+	}
+
+	if chaincodeRequest.RetryAttempts > 0 {
+		retryOpts := retry.Opts{
+			Attempts:       chaincodeRequest.RetryAttempts,
+			InitialBackoff: retry.DefaultInitialBackoff,
+			MaxBackoff:     retry.DefaultMaxBackoff,
+			BackoffFactor:  retry.DefaultBackoffFactor,
+			RetryableCodes: retry.ChannelClientRetryableCodes,
+		}
+		if chaincodeRequest.RetryBackoff > 0 {
+			retryOpts.InitialBackoff = time.Duration(chaincodeRequest.RetryBackoff) * time.Millisecond
+		}
+		options = append(options, channel.WithRetry(retryOpts))
+	}
+	return options, nil
+}
+
+// 执行已通过校验的链码请求，返回结果不设置HTTP状态码，可以并发调用
+func (controller *FabricSDKController) execChaincode(ctx context.Context, chaincodeRequest *ChaincodeRequest, requestID string) (result Result) {
+	ctx, span := tracing.Start(ctx, "chaincode.exec",
+		attribute.String("fabric.org", chaincodeRequest.OrgName),
+		attribute.String("fabric.channel", chaincodeRequest.ChannelID),
+		attribute.String("fabric.chaincode", chaincodeRequest.ChaincodeID),
+		attribute.String("fabric.fcn", chaincodeRequest.Fcn),
+		attribute.Bool("chaincode.async", chaincodeRequest.Async),
+		attribute.Bool("chaincode.dry_run", chaincodeRequest.DryRun),
+		attribute.String("idempotency.key", requestID),
+	)
+	defer func() { endResultSpan(span, result) }()
+
+	if chaincodeRequest.DryRun {
+		return controller.simulateChaincode(ctx, chaincodeRequest)
+	}
+
+	if requestID != "" {
+		pendingKey := chaincodeRequest.OrgName + ":" + requestID
+		if _, loaded := pendingRequests.LoadOrStore(pendingKey, struct{}{}); loaded {
+			return newErrorResult(IdempotencyConflictError, controller.translate("idempotency_conflict"), requestID)
+		}
+		defer pendingRequests.Delete(pendingKey)
+
+		if result, ok := controller.replayExec(ctx, chaincodeRequest, requestID); ok {
+			span.SetAttributes(attribute.Bool("idempotency.replayed", true))
+			return result
+		}
+	}
+
+	serviceSetup, result := controller.getServiceSetup(ctx, chaincodeRequest, fab.Execute)
+	if result.Code != OK {
+		return result
+	}
+
+	if chaincodeRequest.EventFilter != "" {
+		service.Go(func() {
+			serviceSetup.SetEvent(chaincodeRequest.EventFilter, chaincodeRequest.EventCallbackUrl, sendEventCallback)
 		})
 	}
 
+	if chaincodeRequest.Async {
+		response, err := serviceSetup.ExecuteAsync(chaincodeRequest.Fcn, sdkInit.ToBytesArgs(chaincodeRequest.Args))
+		if err != nil {
+			controller.chaincodeLogger(chaincodeRequest).Errorw("异步提交交易失败", "fcn", chaincodeRequest.Fcn, "error", err)
+			return newSDKErrorResult(controller, ExecCCError, "exec_cc_fail", err)
+		}
+
+		if requestID != "" {
+			saveIdempotencyRecord(ctx, chaincodeRequest, requestID, &response, service.TxPending)
+		}
+
+		state, _ := service.Tracker.Get(string(response.TransactionID))
+		controller.chaincodeLogger(chaincodeRequest).Infow("异步提交交易成功", "fcn", chaincodeRequest.Fcn, "tx_id", response.TransactionID)
+		return Result{Code: OK, Message: controller.translate("exec_cc_submitted"), Data: state}
+	}
+
 	response, err := serviceSetup.Execute(chaincodeRequest.Fcn, sdkInit.ToBytesArgs(chaincodeRequest.Args))
 	if err != nil {
-		fmt.Println(err.Error())
-		return controller.getInternalServerError(ExecCCError, i18n.Translate(controller.Ctx, "exec_cc_fail"), err.Error())
+		controller.chaincodeLogger(chaincodeRequest).Errorw("执行链码失败", "fcn", chaincodeRequest.Fcn, "error", err)
+		return newSDKErrorResult(controller, ExecCCError, "exec_cc_fail", err)
+	}
+
+	if requestID != "" {
+		saveIdempotencyRecord(ctx, chaincodeRequest, requestID, &response, service.TxCommitted)
+	}
+
+	// 交易已经上链，索引失败时不返回错误，避免调用方重试导致重复提交
+	deferred, err := serviceSetup.IndexTx(response.TransactionID, chaincodeRequest.Fcn, sdkInit.ToBytesArgs(chaincodeRequest.Args), response.Payload)
+	if err != nil {
+		return Result{Code: OK, Message: controller.translate("exec_cc_success"), Data: response, Warning: controller.translate("index_tx_fail")}
+	}
+	if deferred {
+		return Result{Code: OK, Message: controller.translate("exec_cc_success"), Data: response, Warning: controller.translate("index_tx_deferred")}
+	}
+
+	controller.chaincodeLogger(chaincodeRequest).Infow("执行链码成功", "fcn", chaincodeRequest.Fcn, "tx_id", response.TransactionID)
+	return Result{Code: OK, Message: controller.translate("exec_cc_success"), Data: response}
+}
+
+// 模拟执行链码，只收集背书，不提交交易
+func (controller *FabricSDKController) simulateChaincode(ctx context.Context, chaincodeRequest *ChaincodeRequest) Result {
+	serviceSetup, result := controller.getServiceSetup(ctx, chaincodeRequest, fab.Execute)
+	if result.Code != OK {
+		return result
+	}
+
+	simulation, err := serviceSetup.Simulate(chaincodeRequest.Fcn, sdkInit.ToBytesArgs(chaincodeRequest.Args))
+	if err != nil {
+		controller.chaincodeLogger(chaincodeRequest).Errorw("模拟执行链码失败", "fcn", chaincodeRequest.Fcn, "error", err)
+		return newSDKErrorResult(controller, SimulateCCError, "simulate_cc_fail", err)
+	}
+
+	if !simulation.Consistent {
+		return newErrorResult(EndorsementMismatchError, controller.translate("endorsement_mismatch"), simulation)
+	}
+	return Result{Code: OK, Message: controller.translate("simulate_cc_success"), Data: simulation}
+}
+
+// 用http发送event对象到callbackUrl，请求头中携带trace context
+func sendEventCallback(ctx context.Context, eventFilter string, eventCallbackUrl string, event *fab.CCEvent) {
+	if event == nil {
+		log.Warnw("不能根据指定的事件ID接收到相应的链码事件", "event_filter", eventFilter)
+		return
+	}
+
+	eventLog := log.With("event_filter", eventFilter, "tx_id", event.TxID, "chaincode", event.ChaincodeID, "block_number", event.BlockNumber)
+	eventLog.Infow("接收到链码事件")
+	if eventCallbackUrl == "" {
+		return
+	}
+
+	eventLog = eventLog.With("callback_url", logger.SafeURL(eventCallbackUrl))
+	data, err := json.Marshal(event)
+	if err != nil {
+		eventLog.Errorw("序列化链码事件失败", "error", err)
+		return
 	}
 
-	txInfoPayload := response.Payload
-	txInfo := &TxInfo{}
-	json.Unmarshal(txInfoPayload, txInfo)
-	timestamp := &timestamp.Timestamp{}
-	json.Unmarshal(txInfo.Timestamp, timestamp)
-	fmt.Println(txInfo)
-	fmt.Println(timestamp.Seconds)
+	ctx, span := tracing.Start(ctx, "event.callback", attribute.String("fabric.tx_id", event.TxID), attribute.String("http.url", logger.SafeURL(eventCallbackUrl)))
+	defer span.End()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, eventCallbackUrl, bytes.NewReader(data))
+	if err != nil {
+		metrics.EventDeliveries.WithLabelValues(metrics.EventCallbackFailed).Inc()
+		eventLog.Errorw("创建事件回调请求失败", "error", err)
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	tracing.Inject(ctx, request.Header)
 
-	block, err :=serviceSetup.QueryBlockByTxID(response.TransactionID)
+	resp, err := http.DefaultClient.Do(request)
 	if err != nil {
-		fmt.Printf("根据id获取交易信息失败: %s\n", err)
-		return controller.getInternalServerError(QueryBlockByIdError, i18n.Translate(controller.Ctx, "query_block_by_id"), err.Error())
+		span.RecordError(err)
+		metrics.EventDeliveries.WithLabelValues(metrics.EventCallbackFailed).Inc()
+		eventLog.Errorw("发送事件回调失败", "error", err)
+		return
 	}
+	defer resp.Body.Close()
 
-	previousHash := hex.EncodeToString(block.Header.PreviousHash)
-	fmt.Printf("区块上一个hash: %s\n", previousHash)
+	if _, err = ioutil.ReadAll(resp.Body); err != nil {
+		metrics.EventDeliveries.WithLabelValues(metrics.EventCallbackFailed).Inc()
+		eventLog.Errorw("读取事件回调响应失败", "status", resp.StatusCode, "error", err)
+		return
+	}
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		metrics.EventDeliveries.WithLabelValues(metrics.EventCallbackFailed).Inc()
+		eventLog.Warnw("事件回调返回错误状态", "status", resp.StatusCode)
+		return
+	}
+	metrics.EventDeliveries.WithLabelValues(metrics.EventCallbackSuccess).Inc()
+	eventLog.Infow("事件回调完成", "status", resp.StatusCode)
+}
 
-	blockTXInfo := new(models.BlockTXInfo)
-	blockTXInfo.Number = block.Header.Number
-	blockTXInfo.PreviousHash = previousHash
-	blockTXInfo.TxId = txInfo.TxID
-	blockTXInfo.Timestamp = timestamp.Seconds
-	blockTXInfo.ChannelId = txInfo.Channel
+// 幂等请求的内容指纹，为交易内容签名原串的sha256，超时、重试等请求选项不影响交易内容，不参与计算
+func idempotencyFingerprint(chaincodeRequest *ChaincodeRequest) string {
+	sum := sha256.Sum256([]byte(argsSignSource(chaincodeRequest)))
+	return hex.EncodeToString(sum[:])
+}
 
-	_, err = models.CreateBlockInfo(blockTXInfo)
+// 根据幂等请求ID返回已保存的执行结果，ok为true时直接返回result。
+// 无法读取幂等记录时拒绝执行：不能确认交易是否已经提交，执行可能导致重复提交，调用方可以稍后用相同的请求ID重试
+func (controller *FabricSDKController) replayExec(ctx context.Context, chaincodeRequest *ChaincodeRequest, requestID string) (Result, bool) {
+	since := time.Now().Add(-service.IdempotencyRetention()).Unix()
+	record, has, err := models.GetIdempotencyRecord(ctx, chaincodeRequest.OrgName, requestID, since)
 	if err != nil {
-		return controller.getInternalServerError(QueryBlockError, i18n.Translate(controller.Ctx, "insert_block_database_fail"), err.Error())
+		controller.requestLogger().Errorw("查询幂等记录失败", "idempotency_key", requestID, "error", err)
+		return newErrorResult(IdempotencyRecordError, controller.translate("idempotency_record_fail"), err.Error()), true
+	}
+	if !has {
+		return Result{Code: OK}, false
+	}
+
+	// 升级前保存的记录没有指纹，不做比较
+	if record.Fingerprint != "" && record.Fingerprint != idempotencyFingerprint(chaincodeRequest) {
+		controller.requestLogger().Warnw("幂等请求ID已用于内容不同的请求", "idempotency_key", requestID, "tx_id", record.TxId)
+		return newErrorResult(IdempotencyMismatchError, controller.translate("idempotency_key_reused"), requestID), true
+	}
+
+	// 异步提交的交易返回当前的交易状态，与第一次请求的返回结构相同
+	if record.Status == service.TxPending {
+		return Result{Code: OK, Message: controller.translate("exec_cc_replayed"), Data: resolveTxState(ctx, record)}, true
 	}
 
-	fmt.Printf("执行链码成功，交易hash:%s\n", response.TransactionID)
-	return Result{OK, i18n.Translate(controller.Ctx, "exec_cc_success"), response}
+	response := channel.Response{
+		TransactionID:    fab.TransactionID(record.TxId),
+		TxValidationCode: pb.TxValidationCode(record.TxValidationCode),
+		Payload:          record.Payload,
+	}
+	return Result{Code: OK, Message: controller.translate("exec_cc_replayed"), Data: response}, true
+}
+
+// 根据交易跟踪或交易历史确定异步提交的交易状态，跟踪中状态未知时再查交易历史，都没有记录时为pending
+func resolveTxState(ctx context.Context, record *models.IdempotencyRecord) service.TxState {
+	tracked, ok := service.Tracker.Get(record.TxId)
+	if ok && tracked.Status != service.TxUnknown {
+		return tracked
+	}
+
+	state := service.TxState{
+		TxID:        record.TxId,
+		Status:      service.TxPending,
+		Payload:     record.Payload,
+		SubmittedAt: record.CreatedAt,
+		UpdatedAt:   record.CreatedAt,
+	}
+	if ok {
+		state = tracked
+	}
+	txHistory, has, err := models.GetTxHistoryByTxId(ctx, record.TxId)
+	if err != nil {
+		log.Warnw("查询交易历史失败", "tx_id", record.TxId, "status", state.Status, "error", err)
+		return state
+	}
+	if has {
+		state.ChannelID = txHistory.ChannelId
+		state.ChaincodeID = txHistory.ChaincodeId
+		state.Status = txHistory.Status
+		state.ValidationCode = txHistory.ValidationCode
+		state.BlockNumber = txHistory.BlockNumber
+		state.UpdatedAt = txHistory.Timestamp
+	}
+	return state
+}
+
+// 保存已提交交易的执行结果，失败时只记录日志，交易已经上链。异步提交时status为pending，不保存验证结果
+func saveIdempotencyRecord(ctx context.Context, chaincodeRequest *ChaincodeRequest, requestID string, response *channel.Response, status string) {
+	orgName := chaincodeRequest.OrgName
+	record := &models.IdempotencyRecord{
+		OrgName:          orgName,
+		RequestId:        requestID,
+		Fingerprint:      idempotencyFingerprint(chaincodeRequest),
+		TxId:             string(response.TransactionID),
+		TxValidationCode: int32(response.TxValidationCode),
+		Status:           status,
+		Payload:          response.Payload,
+		CreatedAt:        time.Now().Unix(),
+	}
+	since := time.Now().Add(-service.IdempotencyRetention()).Unix()
+	if err := models.CreateIdempotencyRecord(ctx, record, since); err != nil {
+		log.Errorw("保存幂等记录失败", "org", orgName, "idempotency_key", requestID, "tx_id", response.TransactionID, "error", err)
+	}
+}
+
+// 查询交易状态
+func (controller *FabricSDKController) GetTxBy(txID string) Result {
+	timestamp, err := controller.Ctx.URLParamInt64("timestamp")
+	if err != nil {
+		return controller.getErrorResult(ParseParamsError, controller.translate("parse_params_fail"), err.Error())
+	}
+
+	src := "txID=" + txID + "&timestamp=" + strconv.FormatInt(timestamp, 10)
+	if result := controller.checkSign(timestamp, controller.Ctx.URLParam("sign"), src); result.Code != OK {
+		return result
+	}
+
+	tracked, ok := service.Tracker.Get(txID)
+	if ok && tracked.Status != service.TxUnknown {
+		return Result{Code: OK, Message: controller.translate("get_tx_status_success"), Data: tracked}
+	}
+
+	// 不在跟踪中或状态未知的交易从数据库中查询，状态未知的交易可能已经由对账加入数据库
+	blockTXInfo, err := models.GetBlockByTxId(controller.context(), &models.BlockTXInfo{TxId: txID})
+	if err != nil {
+		return controller.getErrorResult(QueryBlockError, controller.translate("get_block_fail"), err.Error())
+	}
+	if blockTXInfo.Id == 0 {
+		if ok {
+			return Result{Code: OK, Message: controller.translate("get_tx_status_success"), Data: tracked}
+		}
+		return controller.getErrorResult(TxNotFoundError, controller.translate("tx_not_found"), txID)
+	}
+
+	state := service.TxState{
+		TxID:           blockTXInfo.TxId,
+		ChannelID:      blockTXInfo.ChannelId,
+		Status:         service.TxCommitted,
+		ValidationCode: pb.TxValidationCode_VALID.String(),
+		BlockNumber:    blockTXInfo.Number,
+	}
+	return Result{Code: OK, Message: controller.translate("get_tx_status_success"), Data: state}
+}
+
+// 交易历史查询
+func (controller *FabricSDKController) GetTxHistory() Result {
+	page, err := util.NewPagination(controller.Ctx)
+	if err != nil {
+		return controller.getErrorResult(ParseParamsError, controller.translate("get_page_data_fail"), err.Error())
+	}
+
+	filter := newTxHistoryFilter(controller.Ctx)
+
+	src := txHistorySignSource(filter, page) + "&timestamp=" + strconv.FormatInt(page.Timestamp, 10)
+	if result := controller.checkSign(page.Timestamp, page.Sign, src); result.Code != OK {
+		return result
+	}
+
+	txHistories, count, err := models.SearchTxHistory(controller.context(), filter, page)
+	if err != nil {
+		return controller.getErrorResult(QueryTxHistoryError, controller.translate("get_tx_history_fail"), err.Error())
+	}
+	response := util.BootstrapTableVO{
+		Total: count,
+		Rows:  txHistories,
+	}
+	return Result{Code: OK, Message: controller.translate("get_tx_history_success"), Data: response}
+}
+
+// 从查询参数读取交易历史的过滤条件
+func newTxHistoryFilter(params util.Params) *models.TxHistoryFilter {
+	return &models.TxHistoryFilter{
+		ChaincodeId: params.URLParam("chaincodeID"),
+		Fcn:         params.URLParam("fcn"),
+		OrgName:     params.URLParam("orgName"),
+		UserName:    params.URLParam("userName"),
+		Status:      params.URLParam("status"),
+		StartTime:   params.URLParamInt64Default("startTime", 0),
+		EndTime:     params.URLParamInt64Default("endTime", 0),
+	}
+}
+
+// 交易历史查询的签名原串，不包含时间戳
+func txHistorySignSource(filter *models.TxHistoryFilter, page *util.Pagination) string {
+	return "chaincodeID=" + filter.ChaincodeId + "&fcn=" + filter.Fcn + "&orgName=" + filter.OrgName + "&userName=" + filter.UserName + "&status=" + filter.Status +
+		"&startTime=" + strconv.FormatInt(filter.StartTime, 10) + "&endTime=" + strconv.FormatInt(filter.EndTime, 10) +
+		"&PageNumber=" + strconv.Itoa(page.PageNumber) + "&Limit=" + strconv.Itoa(page.Limit) + page.TimeSignSource()
 }
 
 //测试用http发送event对象到callbackUrl
@@ -292,7 +852,7 @@ func (controller *FabricSDKController) PostCallback() Result {
 		return result
 	}
 
-	return Result{OK, "callbackUrl的event对象", event}
+	return Result{Code: OK, Message: "callbackUrl的event对象", Data: event}
 }
 
 // 链码查询
@@ -301,115 +861,356 @@ func (controller *FabricSDKController) PostChaincodeQuery() Result {
 	if result := controller.parseJson(chaincodeRequest); result.Code != OK {
 		return result
 	}
+	return controller.query(chaincodeRequest)
+}
 
+// 查询链码，REST和gRPC共用
+func (controller *FabricSDKController) query(chaincodeRequest *ChaincodeRequest) Result {
 	if len(chaincodeRequest.Args) < 1 {
-		return controller.getInternalServerError(QueryCCError, i18n.Translate(controller.Ctx, "cc_args_len_error", 1), nil)
+		return controller.getErrorResult(ArgsError, controller.translate("cc_args_len_error", 1), nil)
 	}
 
-	src := "args[0]=" + chaincodeRequest.Args[0] + "&channelID=" + chaincodeRequest.ChannelID + "&orgName=" + chaincodeRequest.OrgName + "&userName=" + chaincodeRequest.UserName
-	if len(chaincodeRequest.Args) > 1 {
-		for i := 1; i < len(chaincodeRequest.Args); i++ {
-			src += "&args[" + strconv.Itoa(i) + "]=" + chaincodeRequest.Args[i]
-		}
-	}
-	src += "&chaincodeID=" + chaincodeRequest.ChaincodeID + "&fcn=" + chaincodeRequest.Fcn + "&timestamp=" + strconv.FormatInt(chaincodeRequest.Timestamp, 10)
+	controller.addChaincodeFields(chaincodeRequest)
+	src := querySignSource(chaincodeRequest) + "&timestamp=" + strconv.FormatInt(chaincodeRequest.Timestamp, 10)
 	if result := controller.checkSign(chaincodeRequest.Timestamp, chaincodeRequest.Sign, src); result.Code != OK {
 		return result
 	}
 
-	serviceSetup, result := controller.getServiceSetup(chaincodeRequest)
+	release, result := controller.acquireOrg(chaincodeRequest.OrgName)
+	if result.Code != OK {
+		return result
+	}
+	defer release()
+
+	serviceSetup, result := controller.getServiceSetup(controller.context(), chaincodeRequest, fab.Query)
 	if result.Code != OK {
+		controller.setStatus(result)
 		return result
 	}
 
 	response, err := serviceSetup.Query(chaincodeRequest.Fcn, sdkInit.ToBytesArgs(chaincodeRequest.Args))
 
 	if err != nil {
-		fmt.Println(err.Error())
-		return controller.getInternalServerError(QueryCCError, i18n.Translate(controller.Ctx, "query_cc_fail"), err.Error())
+		controller.requestLogger().Errorw("查询链码失败", "fcn", chaincodeRequest.Fcn, "error", err)
+		result = newSDKErrorResult(controller, QueryCCError, "query_cc_fail", err)
+		controller.setStatus(result)
+		return result
 	}
 
-	fmt.Println(response.Responses[0].Timestamp)
-	return Result{OK, i18n.Translate(controller.Ctx, "query_cc_success"), response}
+	return Result{Code: OK, Message: controller.translate("query_cc_success"), Data: response}
 }
 
 //区块分页查询
 func (controller *FabricSDKController) GetPaginationBlock() Result {
 	page, err := util.NewPagination(controller.Ctx)
 	if err != nil {
-		return controller.getInternalServerError(iris.StatusInternalServerError, i18n.Translate(controller.Ctx, "get_page_data_fail"), err.Error())
+		return controller.getErrorResult(ParseParamsError, controller.translate("get_page_data_fail"), err.Error())
 	}
+	return controller.paginationBlock(page)
+}
 
-	src:="SortOrder="+page.SortOrder+"&PageNumber="+strconv.Itoa(page.PageNumber)+"&SortName="+page.SortName+"&StartDate="+"&Limit="+strconv.Itoa(page.Limit)+ "&timestamp=" + strconv.FormatInt(page.Timestamp, 10)
-	if result := checkSign(controller.Ctx,page.Timestamp,page.Sign,src); result.Code != OK {
+// 区块分页查询的签名原串，不包含时间戳
+func blockSignSource(page *util.Pagination) string {
+	return "SortOrder=" + page.SortOrder + "&PageNumber=" + strconv.Itoa(page.PageNumber) + "&SortName=" + page.SortName + "&StartDate=" + page.StartDate + "&Limit=" + strconv.Itoa(page.Limit) +
+		page.FilterSignSource()
+}
+
+// 按已解析的分页参数查询区块，REST和gRPC共用
+func (controller *FabricSDKController) paginationBlock(page *util.Pagination) Result {
+	src := blockSignSource(page) + "&timestamp=" + strconv.FormatInt(page.Timestamp, 10)
+	if result := controller.checkSign(page.Timestamp, page.Sign, src); result.Code != OK {
 		return result
 	}
 
-	blocks, count, err := models.GetPaginationBlock(page)
+	if !models.ValidBlockSort(page.SortName, page.SortOrder) {
+		return controller.getErrorResult(SortParamsError, controller.translate("sort_params_error"), page.SortName)
+	}
+
+	blocks, count, err := models.GetPaginationBlock(controller.context(), page)
 	if err != nil {
-		return controller.getInternalServerError(iris.StatusInternalServerError, i18n.Translate(controller.Ctx, "get_block_fail"), err.Error())
+		return controller.getErrorResult(QueryBlockError, controller.translate("get_block_fail"), err.Error())
 	}
 	response := util.BootstrapTableVO{
 		Total: count,
 		Rows:  blocks,
 	}
-	return Result{OK, i18n.Translate(controller.Ctx, "get_block_success"), response}
+	return Result{Code: OK, Message: controller.translate("get_block_success"), Data: response}
+}
+
+//区块游标分页查询
+func (controller *FabricSDKController) GetPaginationBlockCursor() Result {
+	page, err := util.NewCursorPagination(controller.Ctx)
+	if err != nil {
+		return controller.getErrorResult(ParseParamsError, controller.translate("get_page_data_fail"), err.Error())
+	}
+	return controller.paginationBlockCursor(page)
+}
+
+// 区块游标分页查询的签名原串，不包含时间戳
+func blockCursorSignSource(page *util.Pagination) string {
+	return "Cursor=" + page.Cursor + "&SortOrder=" + page.SortOrder + "&StartDate=" + page.StartDate + "&Limit=" + strconv.Itoa(page.Limit) +
+		page.FilterSignSource()
+}
+
+// 按已解析的游标分页参数查询区块，REST和gRPC共用
+func (controller *FabricSDKController) paginationBlockCursor(page *util.Pagination) Result {
+	src := blockCursorSignSource(page) + "&timestamp=" + strconv.FormatInt(page.Timestamp, 10)
+	if result := controller.checkSign(page.Timestamp, page.Sign, src); result.Code != OK {
+		return result
+	}
+
+	if page.SortOrder != "asc" && page.SortOrder != "desc" {
+		return controller.getErrorResult(SortParamsError, controller.translate("sort_params_error"), page.SortOrder)
+	}
+	if page.Cursor != "" {
+		if _, _, err := util.DecodeCursor(page.Cursor); err != nil {
+			return controller.getErrorResult(ParseParamsError, controller.translate("get_page_data_fail"), err.Error())
+		}
+	}
+
+	blocks, nextCursor, err := models.GetCursorBlock(controller.context(), page)
+	if err != nil {
+		return controller.getErrorResult(QueryBlockError, controller.translate("get_block_fail"), err.Error())
+	}
+	response := util.CursorVO{
+		Rows:       blocks,
+		NextCursor: nextCursor,
+	}
+	return Result{Code: OK, Message: controller.translate("get_block_success"), Data: response}
+}
+
+//账本与区块索引对账
+func (controller *FabricSDKController) PostBlockReconcile() Result {
+	reconcileRequest := &ReconcileRequest{}
+	if result := controller.parseJson(reconcileRequest); result.Code != OK {
+		return result
+	}
+
+	controller.addFields("org", reconcileRequest.OrgName, "user", reconcileRequest.UserName, "channel", reconcileRequest.ChannelID)
+	src := reconcileSignSource(reconcileRequest) + "&timestamp=" + strconv.FormatInt(reconcileRequest.Timestamp, 10)
+	if result := controller.checkSign(reconcileRequest.Timestamp, reconcileRequest.Sign, src); result.Code != OK {
+		return result
+	}
+
+	serviceSetup, result := controller.getServiceSetup(controller.context(), &ChaincodeRequest{
+		ChannelID: reconcileRequest.ChannelID,
+		OrgName:   reconcileRequest.OrgName,
+		UserName:  reconcileRequest.UserName,
+	}, fab.Query)
+	if result.Code != OK {
+		controller.setStatus(result)
+		return result
+	}
+
+	report, err := serviceSetup.Reconcile(reconcileRequest.StartBlock, reconcileRequest.EndBlock, reconcileRequest.Repair)
+	if err != nil {
+		return controller.getErrorResult(ReconcileError, controller.translate("reconcile_fail"), err.Error())
+	}
+	return Result{Code: OK, Message: controller.translate("reconcile_success"), Data: report}
+}
+
+// 账本对账的签名原串，不包含时间戳
+func reconcileSignSource(reconcileRequest *ReconcileRequest) string {
+	src := "channelID=" + reconcileRequest.ChannelID + "&orgName=" + reconcileRequest.OrgName + "&userName=" + reconcileRequest.UserName +
+		"&startBlock=" + strconv.FormatUint(reconcileRequest.StartBlock, 10) + "&endBlock=" + strconv.FormatUint(reconcileRequest.EndBlock, 10)
+	if reconcileRequest.Repair {
+		src += "&repair=true"
+	}
+	return src
+}
+
+//节点诊断
+func (controller *FabricSDKController) GetDiagnostics() Result {
+	timestamp, err := controller.Ctx.URLParamInt64("timestamp")
+	if err != nil {
+		return controller.getErrorResult(ParseParamsError, controller.translate("parse_params_fail"), err.Error())
+	}
+
+	src := "timestamp=" + strconv.FormatInt(timestamp, 10)
+	if result := controller.checkSign(timestamp, controller.Ctx.URLParam("sign"), src); result.Code != OK {
+		return result
+	}
+
+	return Result{Code: OK, Message: controller.translate("diagnostics_success"), Data: service.Diagnose()}
+}
+
+//错误目录，错误码对应的字符串标识和HTTP状态码，不需要签名
+func (controller *FabricSDKController) GetErrors() Result {
+	return Result{Code: OK, Message: controller.translate("get_errors_success"), Data: ErrorCatalog()}
 }
 
 func (controller *FabricSDKController) parseJson(jsonObjectPtr interface{}) Result {
 	return parseJson(controller.Ctx, jsonObjectPtr)
 }
 
+func (controller *FabricSDKController) acquireOrg(orgName string) (func(), Result) {
+	release, result := acquireOrg(controller, orgName)
+	if result.Code != OK {
+		controller.setStatus(result)
+		return nil, result
+	}
+	return release, result
+}
+
 func (controller *FabricSDKController) checkSign(timestamp int64, sign string, src string) Result {
-	return checkSign(controller.Ctx, timestamp, sign, src)
+	result := verifySign(controller, timestamp, sign, src)
+	if result.Code != OK {
+		controller.setStatus(result)
+	}
+	return result
 }
 
-func (controller *FabricSDKController) getServiceSetup(chaincodeRequest *ChaincodeRequest) (*service.Setup, Result) {
-	client, result := controller.getAndCheckClient(chaincodeRequest.OrgName)
+// 获取链码服务，返回结果不设置HTTP状态码，ctx为后续调用span的父span
+func (controller *FabricSDKController) getServiceSetup(ctx context.Context, chaincodeRequest *ChaincodeRequest, timeoutType fab.TimeoutType) (_ *service.Setup, result Result) {
+	_, span := tracing.Start(ctx, "service.setup", attribute.String("fabric.org", chaincodeRequest.OrgName), attribute.String("fabric.channel", chaincodeRequest.ChannelID))
+	defer func() { endResultSpan(span, result) }()
+
+	client, result := controller.lookupClient(chaincodeRequest.OrgName)
 	if result.Code != OK {
 		return nil, result
 	}
 
-	var err error
-	key := chaincodeRequest.ChannelID + chaincodeRequest.OrgName + chaincodeRequest.UserName
 	channelClientRequest := &sdkInit.ChannelClientRequest{
 		ChannelID: chaincodeRequest.ChannelID,
 		OrgName:   chaincodeRequest.OrgName,
 		UserName:  chaincodeRequest.UserName,
 	}
 
-	channelClient, ok := client.ChannelClients[key]
-	if !ok {
-		channelClient, err = client.NewChannelClient(channelClientRequest)
-		if err != nil {
-			fmt.Println(err.Error())
-			return nil, controller.getInternalServerError(NewChannelClientError, i18n.Translate(controller.Ctx, "new_channelclient_fail"), err.Error())
-		}
-		client.ChannelClients[key] = channelClient
+	channelClient, err := client.GetChannelClient(channelClientRequest)
+	if err != nil {
+		controller.chaincodeLogger(chaincodeRequest).Errorw("创建通道客户端失败", "error", err)
+		return nil, newErrorResult(NewChannelClientError, controller.translate("new_channelclient_fail"), err.Error())
 	}
 
-	ledgerClient,ok := client.LedgerClients[key]
-	if !ok {
-		ledgerClient, err = client.NewLedgerClient(channelClientRequest)
-		if err != nil {
-			fmt.Println(err.Error())
-			return nil, controller.getInternalServerError(NewLedgerClientError, i18n.Translate(controller.Ctx, "new_ledgerclient_fail"), err.Error())
-		}
-		client.LedgerClients[key] = ledgerClient
+	ledgerClient, err := client.GetLedgerClient(channelClientRequest)
+	if err != nil {
+		controller.chaincodeLogger(chaincodeRequest).Errorw("创建账本客户端失败", "error", err)
+		return nil, newErrorResult(NewLedgerClientError, controller.translate("new_ledgerclient_fail"), err.Error())
 	}
 
-	serviceSetup := &service.Setup{ChaincodeID: chaincodeRequest.ChaincodeID, Client: channelClient,LClient: ledgerClient}
+	options, err := requestOptions(client, chaincodeRequest, timeoutType)
+	if err != nil {
+		return nil, newErrorResult(RequestOptionsError, controller.translate("request_options_error"), err.Error())
+	}
+
+	serviceSetup := &service.Setup{
+		ChannelID:   chaincodeRequest.ChannelID,
+		OrgName:     chaincodeRequest.OrgName,
+		UserName:    chaincodeRequest.UserName,
+		ChaincodeID: chaincodeRequest.ChaincodeID,
+		Client:      channelClient,
+		LClient:     ledgerClient,
+		Options:     options,
+		RequestID:   controller.requestID(),
+		Context:     ctx,
+	}
 	return serviceSetup, Result{Code: OK}
 }
 
+// 结束span，结果不成功时记录错误码和信息
+func endResultSpan(span trace.Span, result Result) {
+	span.SetAttributes(attribute.Int("result.code", result.Code))
+	if result.Code != OK {
+		tracing.End(span, fmt.Errorf("%d: %s", result.Code, result.Message))
+		return
+	}
+	span.End()
+}
+
+// 附加了请求ID和链码请求字段的日志，批量执行时各请求并发使用
+func (controller *FabricSDKController) chaincodeLogger(chaincodeRequest *ChaincodeRequest) *zap.SugaredLogger {
+	return log.With("request_id", controller.requestID()).With("org", chaincodeRequest.OrgName, "user", chaincodeRequest.UserName,
+		"channel", chaincodeRequest.ChannelID, "chaincode", chaincodeRequest.ChaincodeID)
+}
+
+// 为单个链码请求的访问日志附加请求字段
+func (controller *FabricSDKController) addChaincodeFields(chaincodeRequest *ChaincodeRequest) {
+	controller.addFields("org", chaincodeRequest.OrgName, "user", chaincodeRequest.UserName,
+		"channel", chaincodeRequest.ChannelID, "chaincode", chaincodeRequest.ChaincodeID)
+}
+
 func (controller *FabricSDKController) getAndCheckClient(orgName string) (*sdkInit.Client, Result) {
+	client, result := controller.lookupClient(orgName)
+	if result.Code != OK {
+		controller.setStatus(result)
+	}
+	return client, result
+}
+
+// 获取已初始化的组织客户端，返回结果不设置HTTP状态码，组织初始化失败时返回其状态
+func (controller *FabricSDKController) lookupClient(orgName string) (*sdkInit.Client, Result) {
 	client, ok := controller.ClientMap[orgName]
 	if !ok {
-		return nil, controller.getInternalServerError(GetAndCheckClientError, i18n.Translate(controller.Ctx, "get_and_check_client", orgName), orgName)
+		return nil, newErrorResult(GetAndCheckClientError, controller.translate("get_and_check_client", orgName), orgName)
+	}
+	if !client.Ready() {
+		return nil, newErrorResult(ClientUnavailableError, controller.translate("client_unavailable", orgName), client.Status())
 	}
 	return client, Result{Code: OK}
 }
 
-func (controller *FabricSDKController) getInternalServerError(code int, message string, data interface{}) Result {
-	return getInternalServerError(controller.Ctx, code, message, data)
+func (controller *FabricSDKController) getErrorResult(code int, message string, data interface{}) Result {
+	result := newErrorResult(code, message, data)
+	controller.setStatus(result)
+	return result
+}
+
+// 按错误码设置HTTP状态码，被限流时同时设置Retry-After，gRPC调用时不设置，由GRPCService转换为gRPC状态码
+func (controller *FabricSDKController) setStatus(result Result) {
+	if controller.rpc != nil {
+		return
+	}
+	if result.Code == RateLimitedError {
+		rateLimited(controller.Ctx, result)
+		return
+	}
+	setErrorStatus(controller.Ctx, result)
+}
+
+// 请求的context，用于链路追踪
+func (controller *FabricSDKController) context() context.Context {
+	if controller.rpc != nil {
+		return controller.rpc.ctx
+	}
+	return tracing.Context(controller.Ctx)
+}
+
+// 按请求的语言翻译提示
+func (controller *FabricSDKController) translate(format string, args ...interface{}) string {
+	if controller.rpc != nil {
+		return contribi18n.Tr(controller.rpc.lang, format, args...)
+	}
+	return i18n.Translate(controller.Ctx, format, args...)
+}
+
+// 附加了请求ID和请求字段的日志
+func (controller *FabricSDKController) requestLogger() *zap.SugaredLogger {
+	if controller.rpc != nil {
+		return log.With("request_id", controller.rpc.requestID).With(controller.rpc.fields...)
+	}
+	return logger.FromContext(controller.Ctx)
+}
+
+func (controller *FabricSDKController) requestID() string {
+	if controller.rpc != nil {
+		return controller.rpc.requestID
+	}
+	return logger.RequestID(controller.Ctx)
+}
+
+// 为请求之后的日志附加字段
+func (controller *FabricSDKController) addFields(keysAndValues ...interface{}) {
+	if controller.rpc != nil {
+		controller.rpc.fields = append(controller.rpc.fields, keysAndValues...)
+		return
+	}
+	logger.AddFields(controller.Ctx, keysAndValues...)
+}
+
+// 请求头或gRPC metadata中的幂等请求ID
+func (controller *FabricSDKController) idempotencyKey() string {
+	if controller.rpc != nil {
+		return controller.rpc.idempotencyKey
+	}
+	return controller.Ctx.GetHeader(IdempotencyKeyHeader)
 }
@@ -0,0 +1,613 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fabric-client/grpcapi"
+	"fabric-client/logger"
+	"fabric-client/models"
+	"fabric-client/ratelimit"
+	"fabric-client/sdkInit"
+	"fabric-client/service"
+	"fabric-client/tracing"
+	"fabric-client/util"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contribi18n "github.com/iris-contrib/i18n"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC请求metadata的key，与REST接口的请求头对应
+const (
+	APIKeyMetadata         = "x-api-key"
+	RequestIDMetadata      = "x-request-id"
+	IdempotencyKeyMetadata = "idempotency-key"
+	LangMetadata           = "lang"
+)
+
+// 调用失败时trailer中的key
+const (
+	errorCodeTrailer  = "error-code"
+	errorIDTrailer    = "error-id"
+	errorDataTrailer  = "error-data"
+	retryAfterTrailer = "retry-after"
+)
+
+// 未指定语言时使用的语言，与i18n中间件的默认语言一致
+const defaultLang = "en"
+
+// gRPC调用的上下文，由拦截器创建，代替REST请求中的iris.Context
+type rpcCall struct {
+	ctx            context.Context
+	lang           string
+	requestID      string
+	idempotencyKey string
+	fields         []interface{} // 访问日志附加的字段
+}
+
+type rpcCallKey struct{}
+
+// 根据metadata创建调用上下文，并设置返回给调用方的请求ID
+func newRPCCall(ctx context.Context) *rpcCall {
+	md, _ := metadata.FromIncomingContext(ctx)
+	call := &rpcCall{
+		lang:           firstMetadata(md, LangMetadata),
+		requestID:      logger.ResolveRequestID(firstMetadata(md, RequestIDMetadata)),
+		idempotencyKey: firstMetadata(md, IdempotencyKeyMetadata),
+	}
+	if call.lang == "" {
+		call.lang = defaultLang
+	}
+	call.ctx = context.WithValue(ctx, rpcCallKey{}, call)
+	return call
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// 调用方标识，与REST接口一致
+func rpcAPIKey(ctx context.Context, md metadata.MD) string {
+	return ratelimit.Caller(firstMetadata(md, APIKeyMetadata), remoteHost(ctx))
+}
+
+// 客户端IP，不含端口
+func remoteHost(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// GRPCServer gRPC服务，停止时先结束事件订阅，再等待处理中的调用完成
+type GRPCServer struct {
+	server  *grpc.Server
+	service *GRPCService
+}
+
+// NewGRPCServer 创建gRPC服务，与REST接口共用组织客户端和限流配置，限流的路由为完整方法名，如/fabricclient.Fabric/Exec
+func NewGRPCServer(clientMap map[string]*sdkInit.Client) *GRPCServer {
+	grpcService := &GRPCService{ClientMap: clientMap, closing: make(chan struct{})}
+	server := grpc.NewServer(grpc.UnaryInterceptor(unaryInterceptor), grpc.StreamInterceptor(streamInterceptor))
+	grpcapi.RegisterFabricServer(server, grpcService)
+	return &GRPCServer{server: server, service: grpcService}
+}
+
+func (server *GRPCServer) Serve(listener net.Listener) error {
+	return server.server.Serve(listener)
+}
+
+// Shutdown 结束事件订阅并停止接收新调用，在ctx到期前等待处理中的调用完成，到期时强制关闭连接
+func (server *GRPCServer) Shutdown(ctx context.Context) error {
+	server.service.closeOnce.Do(func() { close(server.service.closing) })
+
+	done := make(chan struct{})
+	go func() {
+		server.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		server.server.Stop()
+		return ctx.Err()
+	}
+}
+
+// 一元调用的拦截器，设置请求ID、链路追踪和限流，调用结束后记录访问日志
+func unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	call := newRPCCall(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, call.requestID))
+
+	spanCtx, span := tracing.Start(call.ctx, info.FullMethod, attribute.String("rpc.system", "grpc"), attribute.String("request_id", call.requestID))
+	call.ctx = spanCtx
+	start := time.Now()
+	defer func() {
+		tracing.End(span, err)
+		logAccess(call, info.FullMethod, start, err)
+	}()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	release, retryAfter, scope, ok := ratelimit.AcquireRequest(rpcAPIKey(ctx, md), info.FullMethod)
+	if !ok {
+		call.fields = append(call.fields, "rate_limit_scope", scope)
+		result := newRateLimitedResult(contribi18n.Tr(call.lang, "rate_limited"), scope, retryAfter)
+		grpc.SetTrailer(ctx, errorTrailer(result))
+		return nil, resultError(result)
+	}
+	defer release()
+
+	return handler(call.ctx, req)
+}
+
+// 流式调用的拦截器，订阅是长连接，只按速率限制，不占用并发配额
+func streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	call := newRPCCall(stream.Context())
+	stream.SetHeader(metadata.Pairs(RequestIDMetadata, call.requestID))
+
+	spanCtx, span := tracing.Start(call.ctx, info.FullMethod, attribute.String("rpc.system", "grpc"), attribute.String("request_id", call.requestID))
+	call.ctx = spanCtx
+	start := time.Now()
+	defer func() {
+		tracing.End(span, err)
+		logAccess(call, info.FullMethod, start, err)
+	}()
+
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	release, retryAfter, scope, ok := ratelimit.AcquireRequest(rpcAPIKey(stream.Context(), md), info.FullMethod)
+	if !ok {
+		call.fields = append(call.fields, "rate_limit_scope", scope)
+		result := newRateLimitedResult(contribi18n.Tr(call.lang, "rate_limited"), scope, retryAfter)
+		stream.SetTrailer(errorTrailer(result))
+		return resultError(result)
+	}
+	release()
+
+	return handler(srv, &rpcServerStream{ServerStream: stream, ctx: call.ctx})
+}
+
+// 替换context的服务端流，使处理函数能取到调用上下文
+type rpcServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *rpcServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+// 记录gRPC调用的访问日志，字段与REST接口的访问日志对应
+func logAccess(call *rpcCall, method string, start time.Time, err error) {
+	logger.Access().With(call.fields...).Infow("request",
+		"request_id", call.requestID,
+		"method", "GRPC",
+		"path", method,
+		"status", status.Code(err).String(),
+		"latency", time.Since(start),
+		"remote", remoteAddr(call.ctx))
+}
+
+func remoteAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// 错误结果转换为gRPC错误，状态码按错误目录转换
+func resultError(result Result) error {
+	return status.Error(GRPCCode(result.Code), result.Message)
+}
+
+// 错误结果的trailer，包含错误码、字符串标识和JSON格式的Data，被限流时包含建议的重试等待秒数
+func errorTrailer(result Result) metadata.MD {
+	trailer := metadata.Pairs(errorCodeTrailer, strconv.Itoa(result.Code), errorIDTrailer, result.Error)
+	if result.Data != nil {
+		if data, err := json.Marshal(result.Data); err == nil {
+			trailer.Set(errorDataTrailer, string(data))
+		}
+	}
+	if info, ok := result.Data.(RateLimitInfo); ok {
+		trailer.Set(retryAfterTrailer, strconv.Itoa(info.RetryAfter))
+	}
+	return trailer
+}
+
+// GRPCService 实现grpcapi.FabricServer，每次调用创建FabricSDKController，与REST接口共用业务逻辑
+type GRPCService struct {
+	grpcapi.UnimplementedFabricServer
+	ClientMap map[string]*sdkInit.Client
+
+	closing   chan struct{} // 服务停止时关闭，结束事件订阅
+	closeOnce sync.Once
+}
+
+// 创建调用使用的控制器，调用上下文由拦截器创建，未经过拦截器时在此创建
+func (grpcService *GRPCService) controller(ctx context.Context) *FabricSDKController {
+	call, ok := ctx.Value(rpcCallKey{}).(*rpcCall)
+	if !ok {
+		call = newRPCCall(ctx)
+	}
+	return &FabricSDKController{ClientMap: grpcService.ClientMap, rpc: call}
+}
+
+// 结果不成功时设置trailer并返回gRPC错误
+func unaryResult(ctx context.Context, result Result) error {
+	if result.Code == OK {
+		return nil
+	}
+	grpc.SetTrailer(ctx, errorTrailer(result))
+	return resultError(result)
+}
+
+func (grpcService *GRPCService) CreateChannel(ctx context.Context, request *grpcapi.ChannelRequest) (*grpcapi.StatusReply, error) {
+	result := grpcService.controller(ctx).createChannel(toChannelRequest(request))
+	if err := unaryResult(ctx, result); err != nil {
+		return nil, err
+	}
+	return &grpcapi.StatusReply{Message: result.Message}, nil
+}
+
+func (grpcService *GRPCService) JoinChannel(ctx context.Context, request *grpcapi.ChannelRequest) (*grpcapi.StatusReply, error) {
+	result := grpcService.controller(ctx).joinChannel(toChannelRequest(request))
+	if err := unaryResult(ctx, result); err != nil {
+		return nil, err
+	}
+	return &grpcapi.StatusReply{Message: result.Message}, nil
+}
+
+func (grpcService *GRPCService) InstallChaincode(ctx context.Context, request *grpcapi.CCRequest) (*grpcapi.StatusReply, error) {
+	result := grpcService.controller(ctx).installCC(toCCRequest(request))
+	if err := unaryResult(ctx, result); err != nil {
+		return nil, err
+	}
+	return &grpcapi.StatusReply{Message: result.Message}, nil
+}
+
+func (grpcService *GRPCService) InstantiateChaincode(ctx context.Context, request *grpcapi.CCRequest) (*grpcapi.StatusReply, error) {
+	result := grpcService.controller(ctx).instantiateCC(toCCRequest(request))
+	if err := unaryResult(ctx, result); err != nil {
+		return nil, err
+	}
+	return &grpcapi.StatusReply{Message: result.Message}, nil
+}
+
+func (grpcService *GRPCService) UpgradeChaincode(ctx context.Context, request *grpcapi.CCRequest) (*grpcapi.StatusReply, error) {
+	result := grpcService.controller(ctx).upgradeCC(toCCRequest(request))
+	if err := unaryResult(ctx, result); err != nil {
+		return nil, err
+	}
+	return &grpcapi.StatusReply{Message: result.Message}, nil
+}
+
+func (grpcService *GRPCService) Exec(ctx context.Context, request *grpcapi.ChaincodeRequest) (*grpcapi.ChaincodeReply, error) {
+	result := grpcService.controller(ctx).exec(toChaincodeRequest(request))
+	if err := unaryResult(ctx, result); err != nil {
+		return nil, err
+	}
+	return toChaincodeReply(result), nil
+}
+
+func (grpcService *GRPCService) Query(ctx context.Context, request *grpcapi.ChaincodeRequest) (*grpcapi.ChaincodeReply, error) {
+	result := grpcService.controller(ctx).query(toChaincodeRequest(request))
+	if err := unaryResult(ctx, result); err != nil {
+		return nil, err
+	}
+	return toChaincodeReply(result), nil
+}
+
+func (grpcService *GRPCService) ListBlocks(ctx context.Context, request *grpcapi.BlocksRequest) (*grpcapi.BlocksReply, error) {
+	controller := grpcService.controller(ctx)
+	page, err := util.NewPagination(blocksParams(request))
+	if err != nil {
+		return nil, unaryResult(ctx, newErrorResult(ParseParamsError, controller.translate("get_page_data_fail"), err.Error()))
+	}
+
+	result := controller.paginationBlock(page)
+	if err := unaryResult(ctx, result); err != nil {
+		return nil, err
+	}
+	response := result.Data.(util.BootstrapTableVO)
+	return &grpcapi.BlocksReply{Message: result.Message, Total: response.Total, Blocks: toBlocks(response.Rows)}, nil
+}
+
+func (grpcService *GRPCService) ListBlocksByCursor(ctx context.Context, request *grpcapi.BlocksRequest) (*grpcapi.BlocksReply, error) {
+	controller := grpcService.controller(ctx)
+	page, err := util.NewCursorPagination(blocksParams(request))
+	if err != nil {
+		return nil, unaryResult(ctx, newErrorResult(ParseParamsError, controller.translate("get_page_data_fail"), err.Error()))
+	}
+
+	result := controller.paginationBlockCursor(page)
+	if err := unaryResult(ctx, result); err != nil {
+		return nil, err
+	}
+	response := result.Data.(util.CursorVO)
+	return &grpcapi.BlocksReply{Message: result.Message, Blocks: toBlocks(response.Rows), NextCursor: response.NextCursor}, nil
+}
+
+// 订阅事件，服务停止时结束推送并正常返回
+func (grpcService *GRPCService) SubscribeEvents(request *grpcapi.EventsRequest, stream grpcapi.Fabric_SubscribeEventsServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-grpcService.closing:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	controller := grpcService.controller(ctx)
+	controller.rpc.ctx = ctx
+	result := controller.subscribeEvents(&EventsRequest{
+		ChannelID:   request.ChannelId,
+		OrgName:     request.OrgName,
+		UserName:    request.UserName,
+		ChaincodeID: request.ChaincodeId,
+		EventFilter: request.EventFilter,
+		Blocks:      request.Blocks,
+		Timestamp:   request.Timestamp,
+		Sign:        request.Sign,
+	}, func(ccEvent *fab.CCEvent) error {
+		return stream.Send(&grpcapi.Event{Chaincode: &grpcapi.ChaincodeEvent{
+			TxId:        ccEvent.TxID,
+			ChaincodeId: ccEvent.ChaincodeID,
+			EventName:   ccEvent.EventName,
+			Payload:     ccEvent.Payload,
+			BlockNumber: ccEvent.BlockNumber,
+			SourceUrl:   ccEvent.SourceURL,
+		}})
+	}, func(blockEvent *fab.FilteredBlockEvent) error {
+		return stream.Send(&grpcapi.Event{Block: toBlockEvent(blockEvent)})
+	})
+	if result.Code != OK {
+		stream.SetTrailer(errorTrailer(result))
+		return resultError(result)
+	}
+	return nil
+}
+
+// 订阅事件的请求，Blocks为true时订阅区块事件，否则订阅ChaincodeID的链码事件
+type EventsRequest struct {
+	ChannelID   string
+	OrgName     string
+	UserName    string
+	ChaincodeID string
+	EventFilter string // 链码事件名的正则表达式
+	Blocks      bool
+	Timestamp   int64
+	Sign        string
+}
+
+// 订阅事件并持续回调，订阅是长连接，不占用组织的并发配额。返回结果表示订阅结束的原因
+func (controller *FabricSDKController) subscribeEvents(eventsRequest *EventsRequest, onChaincodeEvent func(*fab.CCEvent) error, onBlockEvent func(*fab.FilteredBlockEvent) error) Result {
+	controller.addFields("org", eventsRequest.OrgName, "user", eventsRequest.UserName, "channel", eventsRequest.ChannelID, "chaincode", eventsRequest.ChaincodeID)
+	src := "channelID=" + eventsRequest.ChannelID + "&orgName=" + eventsRequest.OrgName + "&userName=" + eventsRequest.UserName +
+		"&chaincodeID=" + eventsRequest.ChaincodeID + "&eventFilter=" + eventsRequest.EventFilter
+	if eventsRequest.Blocks {
+		src += "&blocks=true"
+	}
+	src += "&timestamp=" + strconv.FormatInt(eventsRequest.Timestamp, 10)
+	if result := controller.checkSign(eventsRequest.Timestamp, eventsRequest.Sign, src); result.Code != OK {
+		return result
+	}
+
+	if !eventsRequest.Blocks && eventsRequest.ChaincodeID == "" {
+		return controller.getErrorResult(ArgsError, controller.translate("events_chaincode_required"), nil)
+	}
+
+	client, result := controller.getAndCheckClient(eventsRequest.OrgName)
+	if result.Code != OK {
+		return result
+	}
+
+	eventClient, err := client.NewEventClient(&sdkInit.ChannelClientRequest{
+		ChannelID: eventsRequest.ChannelID,
+		OrgName:   eventsRequest.OrgName,
+		UserName:  eventsRequest.UserName,
+	})
+	if err != nil {
+		controller.requestLogger().Errorw("创建事件客户端失败", "error", err)
+		return controller.getErrorResult(NewEventClientError, controller.translate("new_eventclient_fail"), err.Error())
+	}
+
+	controller.requestLogger().Infow("开始推送事件", "blocks", eventsRequest.Blocks, "event_filter", eventsRequest.EventFilter)
+	if eventsRequest.Blocks {
+		err = service.SubscribeBlockEvents(controller.context(), eventClient, onBlockEvent)
+	} else {
+		err = service.SubscribeChaincodeEvents(controller.context(), eventClient, eventsRequest.ChaincodeID, eventsRequest.EventFilter, onChaincodeEvent)
+	}
+	if err != nil {
+		controller.requestLogger().Warnw("事件订阅异常结束", "error", err)
+		return controller.getErrorResult(SubscribeEventsError, controller.translate("subscribe_events_fail"), err.Error())
+	}
+	return Result{Code: OK, Message: controller.translate("subscribe_events_end")}
+}
+
+func toChannelRequest(request *grpcapi.ChannelRequest) *ChannelRequest {
+	return &ChannelRequest{
+		ChannelID: request.ChannelId,
+		OrgName:   request.OrgName,
+		Timestamp: request.Timestamp,
+		Sign:      request.Sign,
+	}
+}
+
+func toCCRequest(request *grpcapi.CCRequest) *sdkInit.CCRequest {
+	return &sdkInit.CCRequest{
+		ChannelID:        request.ChannelId,
+		OrgName:          request.OrgName,
+		ChaincodeID:      request.ChaincodeId,
+		ChaincodeVersion: request.ChaincodeVersion,
+		ChaincodePath:    request.ChaincodePath,
+		Args:             request.Args,
+		Timestamp:        request.Timestamp,
+		Sign:             request.Sign,
+	}
+}
+
+func toChaincodeRequest(request *grpcapi.ChaincodeRequest) *ChaincodeRequest {
+	return &ChaincodeRequest{
+		ChannelID:        request.ChannelId,
+		OrgName:          request.OrgName,
+		UserName:         request.UserName,
+		ChaincodeID:      request.ChaincodeId,
+		Fcn:              request.Fcn,
+		Args:             request.Args,
+		EventFilter:      request.EventFilter,
+		EventCallbackUrl: request.EventCallbackUrl,
+		RequestID:        request.RequestId,
+		Async:            request.Async,
+		DryRun:           request.DryRun,
+		TargetPeers:      request.TargetPeers,
+		Timeout:          request.Timeout,
+		RetryAttempts:    int(request.RetryAttempts),
+		RetryBackoff:     request.RetryBackoff,
+		Timestamp:        request.Timestamp,
+		Sign:             request.Sign,
+	}
+}
+
+// 区块查询请求转换为REST接口的查询参数，由util按相同规则解析
+func blocksParams(request *grpcapi.BlocksRequest) util.QueryParams {
+	params := url.Values{}
+	params.Set("pageNumber", strconv.Itoa(int(request.PageNumber)))
+	params.Set("pageSize", strconv.Itoa(int(request.PageSize)))
+	params.Set("timestamp", strconv.FormatInt(request.Timestamp, 10))
+	optional := map[string]string{
+		"sortName":   request.SortName,
+		"sortOrder":  request.SortOrder,
+		"cursor":     request.Cursor,
+		"startDate":  request.StartDate,
+		"endDate":    request.EndDate,
+		"channelID":  request.ChannelId,
+		"txIDPrefix": request.TxIdPrefix,
+		"tz":         request.Tz,
+		"timeFormat": request.TimeFormat,
+		"sign":       request.Sign,
+	}
+	for name, value := range optional {
+		if value != "" {
+			params.Set(name, value)
+		}
+	}
+	if request.MinNumber != nil {
+		params.Set("minNumber", strconv.FormatInt(request.MinNumber.Value, 10))
+	}
+	if request.MaxNumber != nil {
+		params.Set("maxNumber", strconv.FormatInt(request.MaxNumber.Value, 10))
+	}
+	return util.QueryParams(params)
+}
+
+// 执行和查询结果转换为gRPC响应，Data按执行方式为交易结果、异步交易状态或模拟执行结果
+func toChaincodeReply(result Result) *grpcapi.ChaincodeReply {
+	reply := &grpcapi.ChaincodeReply{Message: result.Message, Warning: result.Warning}
+	switch data := result.Data.(type) {
+	case channel.Response:
+		reply.Response = toTxResponse(&data)
+	case *channel.Response:
+		reply.Response = toTxResponse(data)
+	case service.TxState:
+		reply.State = toTxState(&data)
+	case *service.SimulationResult:
+		reply.Simulation = toSimulation(data)
+	}
+	return reply
+}
+
+func toTxResponse(response *channel.Response) *grpcapi.TxResponse {
+	return &grpcapi.TxResponse{
+		TransactionId:    string(response.TransactionID),
+		TxValidationCode: int32(response.TxValidationCode),
+		ChaincodeStatus:  response.ChaincodeStatus,
+		Payload:          response.Payload,
+	}
+}
+
+func toTxState(state *service.TxState) *grpcapi.TxState {
+	return &grpcapi.TxState{
+		TxId:           state.TxID,
+		ChannelId:      state.ChannelID,
+		ChaincodeId:    state.ChaincodeID,
+		Status:         state.Status,
+		ValidationCode: state.ValidationCode,
+		BlockNumber:    state.BlockNumber,
+		Payload:        state.Payload,
+		Error:          state.Error,
+		SubmittedAt:    state.SubmittedAt,
+		UpdatedAt:      state.UpdatedAt,
+	}
+}
+
+func toSimulation(simulation *service.SimulationResult) *grpcapi.Simulation {
+	reply := &grpcapi.Simulation{TxId: simulation.TxID, Consistent: simulation.Consistent}
+	for _, endorsement := range simulation.Endorsements {
+		item := &grpcapi.Endorsement{
+			Endorser:        endorsement.Endorser,
+			Status:          endorsement.Status,
+			ChaincodeStatus: endorsement.ChaincodeStatus,
+			Message:         endorsement.Message,
+			Payload:         endorsement.Payload,
+		}
+		for _, rwSet := range endorsement.ReadWriteSets {
+			nsRWSet := &grpcapi.NsReadWriteSet{Namespace: rwSet.Namespace}
+			for _, read := range rwSet.Reads {
+				nsRWSet.Reads = append(nsRWSet.Reads, &grpcapi.KVRead{Key: read.Key, BlockNum: read.BlockNum, TxNum: read.TxNum})
+			}
+			for _, write := range rwSet.Writes {
+				nsRWSet.Writes = append(nsRWSet.Writes, &grpcapi.KVWrite{Key: write.Key, IsDelete: write.IsDelete, Value: write.Value})
+			}
+			item.ReadWriteSets = append(item.ReadWriteSets, nsRWSet)
+		}
+		reply.Endorsements = append(reply.Endorsements, item)
+	}
+	return reply
+}
+
+func toBlocks(rows interface{}) []*grpcapi.Block {
+	blockTXInfos, _ := rows.([]*models.BlockTXInfo)
+	blocks := make([]*grpcapi.Block, 0, len(blockTXInfos))
+	for _, info := range blockTXInfos {
+		blocks = append(blocks, &grpcapi.Block{
+			Id:           int64(info.Id),
+			Number:       info.Number,
+			PreviousHash: info.PreviousHash,
+			TxId:         info.TxId,
+			Timestamp:    info.Timestamp,
+			ChannelId:    info.ChannelId,
+			Creator:      info.Creator,
+			TimestampNs:  info.TimestampNs,
+			Time:         info.Time,
+		})
+	}
+	return blocks
+}
+
+func toBlockEvent(blockEvent *fab.FilteredBlockEvent) *grpcapi.BlockEvent {
+	block := blockEvent.FilteredBlock
+	event := &grpcapi.BlockEvent{ChannelId: block.ChannelId, Number: block.Number, SourceUrl: blockEvent.SourceURL}
+	for _, tx := range block.FilteredTransactions {
+		event.Transactions = append(event.Transactions, &grpcapi.FilteredTransaction{TxId: tx.Txid, ValidationCode: tx.TxValidationCode.String()})
+	}
+	return event
+}
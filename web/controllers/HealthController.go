@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"fabric-client/service"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/middleware/i18n"
+)
+
+// 存活和就绪检查，不需要签名，供编排系统探测
+type HealthController struct {
+	Ctx iris.Context
+}
+
+// 进程存活
+func (controller *HealthController) GetHealthz() Result {
+	return Result{Code: OK, Message: i18n.Translate(controller.Ctx, "healthz_success")}
+}
+
+// 至少一个组织的SDK可用并已加入通道时就绪，否则返回503。主库和检查未通过的组织列在degraded中，不影响就绪
+func (controller *HealthController) GetReadyz() Result {
+	report := service.Readiness()
+	if !report.Ready {
+		return getErrorResult(controller.Ctx, NotReadyError, i18n.Translate(controller.Ctx, "readyz_fail"), report)
+	}
+	return Result{Code: OK, Message: i18n.Translate(controller.Ctx, "readyz_success"), Data: report}
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"fabric-client/logger"
+	"fabric-client/ratelimit"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/middleware/i18n"
+)
+
+// 调用方标识的请求头，未设置或不在rateLimit.apiKeys中时按客户端IP限流
+const APIKeyHeader = "X-API-Key"
+
+// 被限流时返回的信息，RetryAfter为建议的重试等待秒数
+type RateLimitInfo struct {
+	Scope      string `json:"scope"`
+	RetryAfter int    `json:"retryAfter"`
+}
+
+// RateLimit 按调用方和调用方对路由限流的中间件，超过限制时返回429和Retry-After
+func RateLimit() iris.Handler {
+	return func(ctx iris.Context) {
+		route := ctx.Path()
+		if currentRoute := ctx.GetCurrentRoute(); currentRoute != nil {
+			route = currentRoute.Path()
+		}
+
+		release, retryAfter, scope, ok := ratelimit.AcquireRequest(apiKey(ctx), route)
+		if !ok {
+			logger.FromContext(ctx).Warnw("请求被限流", "scope", scope, "route", route)
+			ctx.JSON(rateLimited(ctx, newRateLimitedResult(i18n.Translate(ctx, "rate_limited"), scope, retryAfter)))
+			return
+		}
+		defer release()
+
+		ctx.Next()
+	}
+}
+
+// 调用方标识
+func apiKey(ctx iris.Context) string {
+	return ratelimit.Caller(ctx.GetHeader(APIKeyHeader), ctx.RemoteAddr())
+}
+
+// 按组织取限流配额，成功时返回释放配额的函数，返回结果不设置HTTP状态码，可以并发调用
+func acquireOrg(scope requestScope, orgName string) (func(), Result) {
+	release, retryAfter, ok := ratelimit.AcquireOrg(orgName)
+	if !ok {
+		scope.requestLogger().Warnw("请求被限流", "scope", ratelimit.ScopeOrg, "org", orgName)
+		return nil, newRateLimitedResult(scope.translate("rate_limited"), ratelimit.ScopeOrg, retryAfter)
+	}
+	return release, Result{Code: OK}
+}
+
+func newRateLimitedResult(message string, scope string, retryAfter time.Duration) Result {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return newErrorResult(RateLimitedError, message, RateLimitInfo{Scope: scope, RetryAfter: seconds})
+}
+
+// 设置429状态码和Retry-After响应头
+func rateLimited(ctx iris.Context, result Result) Result {
+	if info, ok := result.Data.(RateLimitInfo); ok {
+		ctx.Header("Retry-After", strconv.Itoa(info.RetryAfter))
+	}
+	setErrorStatus(ctx, result)
+	return result
+}
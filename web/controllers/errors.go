@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"fabric-client/service"
+	"net/http"
+
+	"github.com/kataras/iris/v12"
+	"google.golang.org/grpc/codes"
+)
+
+// 错误目录中的一项，ID为不会变化的字符串标识，Status为对应的HTTP状态码
+type ErrorInfo struct {
+	Code   int    `json:"code"`
+	ID     string `json:"id"`
+	Status int    `json:"status"`
+}
+
+// 错误码对应的字符串标识和HTTP状态码，新增错误码时需要加入
+var errorCatalog = map[int]ErrorInfo{
+	ParseParamsError:         {ID: "PARSE_PARAMS_ERROR", Status: http.StatusBadRequest},
+	SignExpiredError:         {ID: "SIGN_EXPIRED", Status: http.StatusUnauthorized},
+	SignInvalidError:         {ID: "SIGN_INVALID", Status: http.StatusUnauthorized},
+	GetAndCheckClientError:   {ID: "UNKNOWN_ORG", Status: http.StatusBadRequest},
+	CreateChannelError:       {ID: "CREATE_CHANNEL_FAILED", Status: http.StatusBadGateway},
+	JoinChannelError:         {ID: "JOIN_CHANNEL_FAILED", Status: http.StatusBadGateway},
+	InstallCCError:           {ID: "INSTALL_CHAINCODE_FAILED", Status: http.StatusBadGateway},
+	InstantiateCCError:       {ID: "INSTANTIATE_CHAINCODE_FAILED", Status: http.StatusBadGateway},
+	UpgradeCCError:           {ID: "UPGRADE_CHAINCODE_FAILED", Status: http.StatusBadGateway},
+	ArgsError:                {ID: "INVALID_ARGS", Status: http.StatusBadRequest},
+	NewChannelClientError:    {ID: "CHANNEL_CLIENT_FAILED", Status: http.StatusInternalServerError},
+	ExecCCError:              {ID: "EXEC_CHAINCODE_FAILED", Status: http.StatusBadGateway},
+	QueryCCError:             {ID: "QUERY_CHAINCODE_FAILED", Status: http.StatusBadGateway},
+	NewLedgerClientError:     {ID: "LEDGER_CLIENT_FAILED", Status: http.StatusInternalServerError},
+	QueryBlockError:          {ID: "QUERY_BLOCK_FAILED", Status: http.StatusInternalServerError},
+	QueryBlockByIdError:      {ID: "QUERY_BLOCK_BY_TXID_FAILED", Status: http.StatusBadGateway},
+	IdempotencyConflictError: {ID: "IDEMPOTENCY_CONFLICT", Status: http.StatusConflict},
+	IdempotencyRecordError:   {ID: "IDEMPOTENCY_RECORD_FAILED", Status: http.StatusServiceUnavailable},
+	TxNotFoundError:          {ID: "TX_NOT_FOUND", Status: http.StatusNotFound},
+	BatchPartialError:        {ID: "BATCH_PARTIAL_FAILURE", Status: http.StatusOK},
+	RequestOptionsError:      {ID: "INVALID_REQUEST_OPTIONS", Status: http.StatusBadRequest},
+	SimulateCCError:          {ID: "SIMULATE_CHAINCODE_FAILED", Status: http.StatusBadGateway},
+	EndorsementMismatchError: {ID: "ENDORSEMENT_MISMATCH", Status: http.StatusBadGateway},
+	QueryTxHistoryError:      {ID: "QUERY_TX_HISTORY_FAILED", Status: http.StatusInternalServerError},
+	SortParamsError:          {ID: "INVALID_SORT_PARAMS", Status: http.StatusBadRequest},
+	ReconcileError:           {ID: "RECONCILE_FAILED", Status: http.StatusInternalServerError},
+	NotReadyError:            {ID: "NOT_READY", Status: http.StatusServiceUnavailable},
+	ClientUnavailableError:   {ID: "CLIENT_UNAVAILABLE", Status: http.StatusServiceUnavailable},
+	RateLimitedError:         {ID: "RATE_LIMITED", Status: http.StatusTooManyRequests},
+	MVCCConflictError:        {ID: "MVCC_CONFLICT", Status: http.StatusConflict},
+	SDKTimeoutError:          {ID: "SDK_TIMEOUT", Status: http.StatusGatewayTimeout},
+	PolicyFailureError:       {ID: "POLICY_FAILURE", Status: http.StatusForbidden},
+	NewEventClientError:      {ID: "EVENT_CLIENT_FAILED", Status: http.StatusInternalServerError},
+	SubscribeEventsError:     {ID: "SUBSCRIBE_EVENTS_FAILED", Status: http.StatusBadGateway},
+	IdempotencyMismatchError: {ID: "IDEMPOTENCY_KEY_REUSED", Status: http.StatusConflict},
+}
+
+// SDK错误分类对应的错误码和提示
+var sdkErrorCodes = map[string]struct {
+	code       int
+	messageKey string
+}{
+	service.ErrorMVCCConflict:        {MVCCConflictError, "mvcc_conflict"},
+	service.ErrorTimeout:             {SDKTimeoutError, "sdk_timeout"},
+	service.ErrorPolicyFailure:       {PolicyFailureError, "policy_failure"},
+	service.ErrorEndorsementMismatch: {EndorsementMismatchError, "endorsement_mismatch"},
+}
+
+// ErrorCatalog 返回按错误码排序的错误目录
+func ErrorCatalog() []ErrorInfo {
+	catalog := make([]ErrorInfo, 0, len(errorCatalog))
+	for code := OK + 1; len(catalog) < len(errorCatalog); code++ {
+		if info, ok := errorCatalog[code]; ok {
+			info.Code = code
+			catalog = append(catalog, info)
+		}
+	}
+	return catalog
+}
+
+// HTTPStatus 错误码对应的HTTP状态码，未加入目录的错误码返回500
+func HTTPStatus(code int) int {
+	if code == OK {
+		return http.StatusOK
+	}
+	if info, ok := errorCatalog[code]; ok {
+		return info.Status
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode 错误码对应的gRPC状态码，按错误目录中的HTTP状态码转换
+func GRPCCode(code int) codes.Code {
+	switch HTTPStatus(code) {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// 错误码对应的字符串标识
+func errorID(code int) string {
+	return errorCatalog[code].ID
+}
+
+// 按错误码设置HTTP状态码
+func setErrorStatus(ctx iris.Context, result Result) {
+	ctx.StatusCode(HTTPStatus(result.Code))
+}
+
+// SDK调用失败时的结果，能分类的错误使用更具体的错误码，否则使用code和messageKey，返回结果不设置HTTP状态码
+func newSDKErrorResult(scope requestScope, code int, messageKey string, err error) Result {
+	if classified, ok := sdkErrorCodes[service.ClassifyError(err)]; ok {
+		code, messageKey = classified.code, classified.messageKey
+	}
+	return newErrorResult(code, scope.translate(messageKey), err.Error())
+}
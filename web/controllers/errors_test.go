@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"bufio"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestErrorCatalog(t *testing.T) {
+	ids := make(map[string]int)
+	for code := OK + 1; code <= IdempotencyMismatchError; code++ {
+		info, ok := errorCatalog[code]
+		if !ok {
+			t.Errorf("错误码%d未加入错误目录", code)
+			continue
+		}
+		if info.ID == "" || info.Status == 0 {
+			t.Errorf("错误码%d的字符串标识或HTTP状态码为空", code)
+		}
+		if other, ok := ids[info.ID]; ok {
+			t.Errorf("错误码%d和%d的字符串标识都为%s", code, other, info.ID)
+		}
+		ids[info.ID] = code
+	}
+
+	catalog := ErrorCatalog()
+	if len(catalog) != len(errorCatalog) {
+		t.Fatalf("错误目录数量为%d，应为%d", len(catalog), len(errorCatalog))
+	}
+	for i := 1; i < len(catalog); i++ {
+		if catalog[i-1].Code >= catalog[i].Code {
+			t.Errorf("错误目录未按错误码排序: %d, %d", catalog[i-1].Code, catalog[i].Code)
+		}
+	}
+}
+
+func TestHTTPStatusAndGRPCCode(t *testing.T) {
+	cases := []struct {
+		code   int
+		status int
+		grpc   codes.Code
+	}{
+		{OK, http.StatusOK, codes.OK},
+		{ParseParamsError, http.StatusBadRequest, codes.InvalidArgument},
+		{SignExpiredError, http.StatusUnauthorized, codes.Unauthenticated},
+		{PolicyFailureError, http.StatusForbidden, codes.PermissionDenied},
+		{TxNotFoundError, http.StatusNotFound, codes.NotFound},
+		{MVCCConflictError, http.StatusConflict, codes.Aborted},
+		{IdempotencyConflictError, http.StatusConflict, codes.Aborted},
+		{RateLimitedError, http.StatusTooManyRequests, codes.ResourceExhausted},
+		{ExecCCError, http.StatusBadGateway, codes.Unavailable},
+		{NotReadyError, http.StatusServiceUnavailable, codes.Unavailable},
+		{SDKTimeoutError, http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{ReconcileError, http.StatusInternalServerError, codes.Internal},
+		{BatchPartialError, http.StatusOK, codes.OK},
+		{999, http.StatusInternalServerError, codes.Internal}, // 未加入目录的错误码
+	}
+	for _, c := range cases {
+		if status := HTTPStatus(c.code); status != c.status {
+			t.Errorf("HTTPStatus(%d) = %d，应为%d", c.code, status, c.status)
+		}
+		if grpcCode := GRPCCode(c.code); grpcCode != c.grpc {
+			t.Errorf("GRPCCode(%d) = %s，应为%s", c.code, grpcCode, c.grpc)
+		}
+	}
+}
+
+// SDK错误分类对应的错误码在错误目录中，提示在各语言文件中都有
+func TestSDKErrorCodes(t *testing.T) {
+	locales := []string{"../../locale/locale_en-US.ini", "../../locale/locale_zh-CN.ini"}
+	keys := make(map[string]map[string]bool)
+	for _, path := range locales {
+		keys[path] = readLocaleKeys(t, path)
+	}
+
+	for classification, sdkError := range sdkErrorCodes {
+		if _, ok := errorCatalog[sdkError.code]; !ok {
+			t.Errorf("%s对应的错误码%d未加入错误目录", classification, sdkError.code)
+		}
+		for _, path := range locales {
+			if !keys[path][sdkError.messageKey] {
+				t.Errorf("%s中没有%s的提示%s", path, classification, sdkError.messageKey)
+			}
+		}
+	}
+}
+
+func readLocaleKeys(t *testing.T, path string) map[string]bool {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	keys := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if i := strings.Index(scanner.Text(), "="); i > 0 {
+			keys[strings.TrimSpace(scanner.Text()[:i])] = true
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"fabric-client/service"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+)
+
+func TestResultTxID(t *testing.T) {
+	cases := []struct {
+		name   string
+		result Result
+		txID   string
+	}{
+		{"sync", Result{Code: OK, Data: channel.Response{TransactionID: "tx1"}}, "tx1"},
+		{"async", Result{Code: OK, Data: service.TxState{TxID: "tx2", Status: service.TxPending}}, "tx2"},
+		{"simulate", Result{Code: OK, Data: &service.SimulationResult{}}, ""},
+		{"failed", newErrorResult(ExecCCError, "failed", "error"), ""},
+	}
+	for _, c := range cases {
+		if txID := resultTxID(c.result); txID != c.txID {
+			t.Errorf("%s: 交易ID为%q，应为%q", c.name, txID, c.txID)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package controllers
+
+import "testing"
+
+func TestIdempotencyFingerprint(t *testing.T) {
+	base := ChaincodeRequest{ChannelID: "mychannel", OrgName: "Org1", UserName: "User1", ChaincodeID: "mycc", Fcn: "invoke", Args: []string{"a", "b", "10"}, RequestID: "req-1"}
+	fingerprint := idempotencyFingerprint(&base)
+
+	// 请求选项和签名不影响交易内容
+	retried := base
+	retried.Timeout, retried.RetryAttempts, retried.Timestamp, retried.Sign = 3000, 2, 1600000000, "s"
+	if idempotencyFingerprint(&retried) != fingerprint {
+		t.Error("只有请求选项不同时指纹应相同")
+	}
+
+	changes := map[string]func(request *ChaincodeRequest){
+		"args":        func(request *ChaincodeRequest) { request.Args = []string{"a", "b", "11"} },
+		"fcn":         func(request *ChaincodeRequest) { request.Fcn = "transfer" },
+		"chaincodeID": func(request *ChaincodeRequest) { request.ChaincodeID = "othercc" },
+		"channelID":   func(request *ChaincodeRequest) { request.ChannelID = "otherchannel" },
+		"userName":    func(request *ChaincodeRequest) { request.UserName = "User2" },
+	}
+	for name, change := range changes {
+		changed := base
+		changed.Args = append([]string(nil), base.Args...)
+		change(&changed)
+		if idempotencyFingerprint(&changed) == fingerprint {
+			t.Errorf("%s不同时指纹应不同", name)
+		}
+	}
+}
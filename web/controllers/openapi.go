@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"fabric-client/models"
+	"fabric-client/openapi"
+	"fabric-client/sdkInit"
+	"fabric-client/service"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+// 文档的标签
+const (
+	tagHealth    = "health"
+	tagChannel   = "channel"
+	tagChaincode = "chaincode"
+	tagTx        = "transaction"
+	tagBlock     = "block"
+	tagOps       = "operations"
+)
+
+const apiDescription = `所有/api接口（/api/errors除外）都需要签名：按各接口x-sign-source的规则拼接签名原串，
+sign为md5(hex(sha512(原串)))的十六进制串，timestamp为unix秒，与服务器时间相差超过120秒时签名过期。
+方括号中的部分只在对应字段有值时拼接。签名错误时Data返回服务端使用的签名原串。`
+
+// 链码请求的签名原串，不包含时间戳
+const (
+	execSignSourceRule = "args[0]={Args[0]}&channelID={ChannelID}&orgName={OrgName}&userName={UserName}[&args[i]={Args[i]}...]" +
+		"&chaincodeID={ChaincodeID}&fcn={Fcn}&eventCallbackUrl={EventCallbackUrl}[&requestID={RequestID}][&async=true][&dryRun=true]" + optionsSignSourceRule
+	optionsSignSourceRule = "[&targetPeers={TargetPeers用,连接}][&timeout={Timeout}][&retryAttempts={RetryAttempts}][&retryBackoff={RetryBackoff}]"
+	filterSignSourceRule  = "[&EndDate={endDate}][&ChannelID={channelID}][&TxIDPrefix={txIDPrefix}][&MinNumber={minNumber}][&MaxNumber={maxNumber}]" + timeSignSourceRule
+	timeSignSourceRule    = "[&Timezone={tz}][&TimeFormat={timeFormat}，unix时不拼接]"
+)
+
+// 签名参数
+var signParams = []openapi.Param{
+	{Name: "timestamp", In: openapi.InQuery, Type: "integer", Required: true, Description: "unix秒"},
+	{Name: "sign", In: openapi.InQuery, Required: true},
+}
+
+// 区块过滤和时间格式参数
+var blockFilterParams = []openapi.Param{
+	{Name: "startDate", In: openapi.InQuery, Description: "开始日期，支持时间戳和2006-01-02 15:04:05、2006-01-02、RFC3339格式"},
+	{Name: "endDate", In: openapi.InQuery, Description: "结束日期，格式同startDate，包含结束时间，只有日期时包含当天"},
+	{Name: "channelID", In: openapi.InQuery},
+	{Name: "txIDPrefix", In: openapi.InQuery},
+	{Name: "minNumber", In: openapi.InQuery, Type: "integer", Description: "最小区块号，-1为不限制"},
+	{Name: "maxNumber", In: openapi.InQuery, Type: "integer", Description: "最大区块号，-1为不限制"},
+	{Name: "tz", In: openapi.InQuery, Description: "时区，如Asia/Shanghai"},
+	{Name: "timeFormat", In: openapi.InQuery, Description: "unix或rfc3339"},
+}
+
+// 各接口的文档，key为控制器方法名
+var endpoints = map[string]openapi.Endpoint{
+	"GetHealthz": {Summary: "进程存活", Tag: tagHealth},
+	"GetReadyz":  {Summary: "服务就绪检查，没有可用组织时返回503，主库和部分组织不可用时在degraded中列出", Tag: tagHealth, Response: service.ReadinessReport{}},
+
+	"PostChannelCreate": {
+		Summary:    "创建通道",
+		Tag:        tagChannel,
+		Request:    ChannelRequest{},
+		SignSource: "orgName={OrgName}&channelID={ChannelID}&timestamp={Timestamp}",
+	},
+	"PostChaincodeJoin": {
+		Summary:    "组织的节点加入通道",
+		Tag:        tagChannel,
+		Request:    ChannelRequest{},
+		SignSource: "orgName={OrgName}&channelID={ChannelID}&timestamp={Timestamp}",
+	},
+
+	"PostChaincodeInstall": {
+		Summary:    "安装链码",
+		Tag:        tagChaincode,
+		Request:    sdkInit.CCRequest{},
+		SignSource: "orgName={OrgName}&chaincodeID={ChaincodeID}&chaincodeVersion={ChaincodeVersion}&chaincodePath={ChaincodePath}&timestamp={Timestamp}",
+	},
+	"PostChaincodeInstantiate": {
+		Summary:    "实例化链码",
+		Tag:        tagChaincode,
+		Request:    sdkInit.CCRequest{},
+		SignSource: "channelID={ChannelID}&orgName={OrgName}&chaincodeID={ChaincodeID}&chaincodeVersion={ChaincodeVersion}&chaincodePath={ChaincodePath}&timestamp={Timestamp}",
+	},
+	"PostChaincodeUpgrade": {
+		Summary:    "升级链码",
+		Tag:        tagChaincode,
+		Request:    sdkInit.CCRequest{},
+		SignSource: "channelID={ChannelID}&orgName={OrgName}&chaincodeID={ChaincodeID}&chaincodeVersion={ChaincodeVersion}&chaincodePath={ChaincodePath}&timestamp={Timestamp}",
+	},
+	"PostChaincodeExec": {
+		Summary: "执行链码",
+		Description: "同步执行时Data为交易结果；Async为true时提交后立即返回，Data为交易状态（TxState），通过/api/tx/{txID}查询；" +
+			"DryRun为true时只收集背书，Data为模拟结果（SimulationResult）。相同的幂等请求ID在保留期内返回第一次的结果，异步提交的交易返回当前的交易状态。",
+		Tag:        tagChaincode,
+		Params:     []openapi.Param{{Name: IdempotencyKeyHeader, In: openapi.InHeader, Description: "幂等请求ID，等同于请求体中的RequestID，按requestID参与签名，与RequestID同时传入时必须相同"}},
+		Request:    ChaincodeRequest{},
+		Response:   channel.Response{},
+		SignSource: execSignSourceRule + "&timestamp={Timestamp}",
+	},
+	"PostChaincodeBatch": {
+		Summary:     "批量执行链码",
+		Description: "各项并发执行，部分失败时Code为20，各项的结果见Items。",
+		Tag:         tagChaincode,
+		Request:     BatchChaincodeRequest{},
+		Response:    BatchResult{},
+		SignSource:  "各项的签名原串用|连接，各项规则同/api/chaincode/exec但不包含timestamp，最后拼接&timestamp={Timestamp}",
+	},
+	"PostChaincodeQuery": {
+		Summary:  "查询链码",
+		Tag:      tagChaincode,
+		Request:  ChaincodeRequest{},
+		Response: channel.Response{},
+		SignSource: "args[0]={Args[0]}&channelID={ChannelID}&orgName={OrgName}&userName={UserName}[&args[i]={Args[i]}...]&chaincodeID={ChaincodeID}&fcn={Fcn}" +
+			optionsSignSourceRule + "&timestamp={Timestamp}",
+	},
+	"PostCallback": {
+		Summary:  "测试事件回调，原样返回收到的事件",
+		Tag:      tagChaincode,
+		Request:  fab.CCEvent{},
+		Response: fab.CCEvent{},
+	},
+
+	"GetTxBy": {
+		Summary:    "查询交易状态",
+		Tag:        tagTx,
+		Params:     append([]openapi.Param{{Name: "txID", In: openapi.InPath}}, signParams...),
+		Response:   service.TxState{},
+		SignSource: "txID={txID}&timestamp={timestamp}",
+	},
+	"GetTxHistory": {
+		Summary: "查询交易历史",
+		Tag:     tagTx,
+		Params: append([]openapi.Param{
+			{Name: "pageNumber", In: openapi.InQuery, Type: "integer", Required: true},
+			{Name: "pageSize", In: openapi.InQuery, Type: "integer", Required: true},
+			{Name: "chaincodeID", In: openapi.InQuery},
+			{Name: "fcn", In: openapi.InQuery},
+			{Name: "orgName", In: openapi.InQuery},
+			{Name: "userName", In: openapi.InQuery},
+			{Name: "status", In: openapi.InQuery},
+			{Name: "startTime", In: openapi.InQuery, Type: "integer", Description: "unix秒，0为不限制"},
+			{Name: "endTime", In: openapi.InQuery, Type: "integer", Description: "unix秒，0为不限制"},
+			{Name: "tz", In: openapi.InQuery},
+			{Name: "timeFormat", In: openapi.InQuery},
+		}, signParams...),
+		Response: struct {
+			Total int64               `json:"total"`
+			Rows  []*models.TxHistory `json:"rows"`
+		}{},
+		SignSource: "chaincodeID={chaincodeID}&fcn={fcn}&orgName={orgName}&userName={userName}&status={status}&startTime={startTime}&endTime={endTime}" +
+			"&PageNumber={pageNumber，最小为1}&Limit={pageSize，最小为1}" + timeSignSourceRule + "&timestamp={timestamp}",
+	},
+
+	"GetPaginationBlock": {
+		Summary: "区块交易分页查询",
+		Tag:     tagBlock,
+		Params: append(append([]openapi.Param{
+			{Name: "pageNumber", In: openapi.InQuery, Type: "integer", Required: true},
+			{Name: "pageSize", In: openapi.InQuery, Type: "integer", Required: true},
+			{Name: "sortName", In: openapi.InQuery},
+			{Name: "sortOrder", In: openapi.InQuery, Description: "asc或desc"},
+		}, blockFilterParams...), signParams...),
+		Response: struct {
+			Total int64                 `json:"total"`
+			Rows  []*models.BlockTXInfo `json:"rows"`
+		}{},
+		SignSource: "SortOrder={sortOrder}&PageNumber={pageNumber，最小为1}&SortName={sortName}&StartDate={startDate}&Limit={pageSize，最小为1}" +
+			filterSignSourceRule + "&timestamp={timestamp}",
+	},
+	"GetPaginationBlockCursor": {
+		Summary: "区块交易游标分页查询",
+		Tag:     tagBlock,
+		Params: append(append([]openapi.Param{
+			{Name: "pageSize", In: openapi.InQuery, Type: "integer", Required: true},
+			{Name: "cursor", In: openapi.InQuery, Description: "上一页返回的nextCursor，为空时从头开始"},
+			{Name: "sortOrder", In: openapi.InQuery, Description: "asc或desc，默认asc"},
+		}, blockFilterParams...), signParams...),
+		Response: struct {
+			Rows       []*models.BlockTXInfo `json:"rows"`
+			NextCursor string                `json:"nextCursor"`
+		}{},
+		SignSource: "Cursor={cursor}&SortOrder={sortOrder，默认asc}&StartDate={startDate}&Limit={pageSize，最小为1}" + filterSignSourceRule + "&timestamp={timestamp}",
+	},
+	"PostBlockReconcile": {
+		Summary:  "账本与区块索引对账",
+		Tag:      tagBlock,
+		Request:  ReconcileRequest{},
+		Response: service.ReconcileReport{},
+		SignSource: "channelID={ChannelID}&orgName={OrgName}&userName={UserName}&startBlock={StartBlock}&endBlock={EndBlock}[&repair=true]" +
+			"&timestamp={Timestamp}",
+	},
+
+	"GetDiagnostics": {
+		Summary:    "组织节点诊断",
+		Tag:        tagOps,
+		Params:     signParams,
+		Response:   []*sdkInit.OrgDiagnostics{},
+		SignSource: "timestamp={timestamp}",
+	},
+	"GetErrors": {
+		Summary:  "错误目录",
+		Tag:      tagOps,
+		Response: []ErrorInfo{},
+	},
+}
+
+// OpenAPI 根据已注册的路由生成接口文档
+func OpenAPI(routes []*router.Route) *openapi.Document {
+	info := openapi.Info{Title: "fabric-client", Version: "1.0", Description: apiDescription}
+	return openapi.Build(info, routes, endpoints)
+}
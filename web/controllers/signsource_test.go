@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fabric-client/client"
+	"fabric-client/sdkInit"
+	"fabric-client/sign"
+	"fabric-client/util"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// 按服务端的规则解析请求并计算签名原串，返回签名原串、时间戳和请求中的签名
+type signSourceParser func(r *http.Request) (src string, timestamp int64, signature string, err error)
+
+// 解析JSON请求体，signedSource由请求体生成不含时间戳的签名原串
+func jsonSource(newRequest func() interface{}, signedSource func(request interface{}) string) signSourceParser {
+	return func(r *http.Request) (string, int64, string, error) {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return "", 0, "", err
+		}
+		request := newRequest()
+		var signed struct {
+			Timestamp int64
+			Sign      string
+		}
+		if err = json.Unmarshal(data, request); err != nil {
+			return "", 0, "", err
+		}
+		if err = json.Unmarshal(data, &signed); err != nil {
+			return "", 0, "", err
+		}
+		return signedSource(request), signed.Timestamp, signed.Sign, nil
+	}
+}
+
+// 解析查询参数中的时间戳和签名
+func querySource(signedSource func(r *http.Request, params util.QueryParams) (string, error)) signSourceParser {
+	return func(r *http.Request) (string, int64, string, error) {
+		params := util.QueryParams(r.URL.Query())
+		timestamp, err := params.URLParamInt64("timestamp")
+		if err != nil {
+			return "", 0, "", err
+		}
+		src, err := signedSource(r, params)
+		return src, timestamp, params.URLParam("sign"), err
+	}
+}
+
+// 与控制器相同的签名原串，路由与client包请求的路径对应
+var signSourceRoutes = map[string]signSourceParser{
+	"POST /api/channel/create": jsonSource(func() interface{} { return &ChannelRequest{} }, func(request interface{}) string {
+		return channelSignSource(request.(*ChannelRequest))
+	}),
+	"POST /api/chaincode/join": jsonSource(func() interface{} { return &ChannelRequest{} }, func(request interface{}) string {
+		return channelSignSource(request.(*ChannelRequest))
+	}),
+	"POST /api/chaincode/install": jsonSource(func() interface{} { return &sdkInit.CCRequest{} }, func(request interface{}) string {
+		return installSignSource(request.(*sdkInit.CCRequest))
+	}),
+	"POST /api/chaincode/instantiate": jsonSource(func() interface{} { return &sdkInit.CCRequest{} }, func(request interface{}) string {
+		return ccSignSource(request.(*sdkInit.CCRequest))
+	}),
+	"POST /api/chaincode/upgrade": jsonSource(func() interface{} { return &sdkInit.CCRequest{} }, func(request interface{}) string {
+		return ccSignSource(request.(*sdkInit.CCRequest))
+	}),
+	"POST /api/chaincode/exec": jsonSource(func() interface{} { return &ChaincodeRequest{} }, func(request interface{}) string {
+		return execSignSource(request.(*ChaincodeRequest))
+	}),
+	"POST /api/chaincode/batch": jsonSource(func() interface{} { return &BatchChaincodeRequest{} }, func(request interface{}) string {
+		return batchSignSource(request.(*BatchChaincodeRequest).Requests)
+	}),
+	"POST /api/chaincode/query": jsonSource(func() interface{} { return &ChaincodeRequest{} }, func(request interface{}) string {
+		return querySignSource(request.(*ChaincodeRequest))
+	}),
+	"POST /api/block/reconcile": jsonSource(func() interface{} { return &ReconcileRequest{} }, func(request interface{}) string {
+		return reconcileSignSource(request.(*ReconcileRequest))
+	}),
+	"GET /api/tx/history": querySource(func(r *http.Request, params util.QueryParams) (string, error) {
+		page, err := util.NewPagination(params)
+		if err != nil {
+			return "", err
+		}
+		return txHistorySignSource(newTxHistoryFilter(params), page), nil
+	}),
+	"GET /api/pagination/block": querySource(func(r *http.Request, params util.QueryParams) (string, error) {
+		page, err := util.NewPagination(params)
+		if err != nil {
+			return "", err
+		}
+		return blockSignSource(page), nil
+	}),
+	"GET /api/pagination/block/cursor": querySource(func(r *http.Request, params util.QueryParams) (string, error) {
+		page, err := util.NewCursorPagination(params)
+		if err != nil {
+			return "", err
+		}
+		return blockCursorSignSource(page), nil
+	}),
+	"GET /api/tx/": querySource(func(r *http.Request, params util.QueryParams) (string, error) {
+		return "txID=" + strings.TrimPrefix(r.URL.Path, "/api/tx/"), nil
+	}),
+	"GET /api/diagnostics": querySource(func(r *http.Request, params util.QueryParams) (string, error) {
+		return "", nil
+	}),
+}
+
+// 按服务端规则校验client包发出的请求的签名，签名不一致时返回签名原串
+func newSignCheckServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.Method + " " + r.URL.Path
+		if strings.HasPrefix(r.URL.Path, "/api/tx/") && r.URL.Path != "/api/tx/history" {
+			route = "GET /api/tx/"
+		}
+		result := Result{Code: OK}
+		if parser, ok := signSourceRoutes[route]; !ok {
+			result = newErrorResult(ArgsError, "unknown route "+route, nil)
+		} else if src, timestamp, signature, err := parser(r); err != nil {
+			result = newErrorResult(ParseParamsError, err.Error(), nil)
+		} else {
+			// 与verifySign相同，签名原串以时间戳结尾
+			src += "&timestamp=" + strconv.FormatInt(timestamp, 10)
+			if strings.HasPrefix(src, "&") {
+				src = src[1:]
+			}
+			if sign.Sign(src) != signature {
+				result = newErrorResult(SignInvalidError, "sign mismatch", src)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}))
+}
+
+func TestClientSignSourceParity(t *testing.T) {
+	server := newSignCheckServer()
+	defer server.Close()
+	c := client.New(server.URL)
+	ctx := context.Background()
+
+	minNumber, maxNumber := int64(0), int64(99)
+	filter := client.BlockFilter{StartDate: "2021-01-01", EndDate: "2021-12-31", ChannelID: "mychannel", TxIDPrefix: "ab",
+		MinNumber: &minNumber, MaxNumber: &maxNumber, Timezone: "Asia/Shanghai", TimeFormat: "rfc3339"}
+	chaincodeRequest := &client.ChaincodeRequest{ChannelID: "mychannel", OrgName: "Org1", UserName: "User1", ChaincodeID: "mycc", Fcn: "invoke",
+		Args: []string{"a", "b", "10"}, EventCallbackUrl: "http://127.0.0.1/callback", RequestID: "req-1",
+		TargetPeers: []string{"peer0.org1.example.com", "peer1.org1.example.com"}, Timeout: 3000, RetryAttempts: 2, RetryBackoff: 500}
+	minimalRequest := &client.ChaincodeRequest{ChannelID: "mychannel", OrgName: "Org1", UserName: "User1", ChaincodeID: "mycc", Fcn: "query", Args: []string{"a"}}
+	ccRequest := &client.CCRequest{ChannelID: "mychannel", OrgName: "Org1", ChaincodeID: "mycc", ChaincodeVersion: "1.0", ChaincodePath: "github.com/mycc"}
+
+	cases := []struct {
+		name string
+		call func() error
+	}{
+		{"CreateChannel", func() error {
+			return c.CreateChannel(ctx, &client.ChannelRequest{ChannelID: "mychannel", OrgName: "Org1"})
+		}},
+		{"JoinChannel", func() error {
+			return c.JoinChannel(ctx, &client.ChannelRequest{ChannelID: "mychannel", OrgName: "Org1"})
+		}},
+		{"InstallChaincode", func() error { return c.InstallChaincode(ctx, ccRequest) }},
+		{"InstantiateChaincode", func() error { return c.InstantiateChaincode(ctx, ccRequest) }},
+		{"UpgradeChaincode", func() error { return c.UpgradeChaincode(ctx, ccRequest) }},
+		{"Exec", func() error { _, err := c.Exec(ctx, chaincodeRequest); return err }},
+		{"ExecMinimal", func() error { _, err := c.Exec(ctx, minimalRequest); return err }},
+		{"ExecAsync", func() error { _, err := c.ExecAsync(ctx, chaincodeRequest); return err }},
+		{"Simulate", func() error { _, err := c.Simulate(ctx, chaincodeRequest); return err }},
+		{"Batch", func() error {
+			_, err := c.Batch(ctx, []*client.ChaincodeRequest{chaincodeRequest, minimalRequest})
+			return err
+		}},
+		{"Query", func() error { _, err := c.Query(ctx, chaincodeRequest); return err }},
+		{"QueryMinimal", func() error { _, err := c.Query(ctx, minimalRequest); return err }},
+		{"TxStatus", func() error { _, err := c.TxStatus(ctx, "0123abcd"); return err }},
+		{"TxHistory", func() error {
+			_, err := c.TxHistory(ctx, &client.TxHistoryQuery{PageNumber: 2, PageSize: 20, ChaincodeID: "mycc", Fcn: "invoke", OrgName: "Org1",
+				UserName: "User1", Status: "committed", StartTime: 1600000000, EndTime: 1700000000, Timezone: "UTC", TimeFormat: "rfc3339"})
+			return err
+		}},
+		{"TxHistoryDefaults", func() error { _, err := c.TxHistory(ctx, &client.TxHistoryQuery{}); return err }},
+		{"Blocks", func() error {
+			_, err := c.Blocks(ctx, &client.BlockQuery{PageNumber: 3, PageSize: 10, SortName: "number", SortOrder: "desc", BlockFilter: filter})
+			return err
+		}},
+		{"BlocksDefaults", func() error { _, err := c.Blocks(ctx, &client.BlockQuery{}); return err }},
+		{"BlocksCursor", func() error {
+			_, err := c.BlocksCursor(ctx, &client.BlockCursorQuery{PageSize: 10, Cursor: util.EncodeCursor(5, 7), SortOrder: "desc", BlockFilter: filter})
+			return err
+		}},
+		{"BlocksCursorDefaults", func() error { _, err := c.BlocksCursor(ctx, &client.BlockCursorQuery{}); return err }},
+		{"Reconcile", func() error {
+			_, err := c.Reconcile(ctx, &client.ReconcileRequest{ChannelID: "mychannel", OrgName: "Org1", UserName: "User1", StartBlock: 1, EndBlock: 9, Repair: true})
+			return err
+		}},
+		{"Diagnostics", func() error { _, err := c.Diagnostics(ctx); return err }},
+	}
+	for _, tc := range cases {
+		if err := tc.call(); err != nil {
+			if apiErr, ok := err.(*client.Error); ok {
+				t.Errorf("%s: %v，服务端签名原串为%s", tc.name, err, apiErr.Data)
+			} else {
+				t.Errorf("%s: %v", tc.name, err)
+			}
+		}
+	}
+}